@@ -0,0 +1,469 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ApiGatewayPayloadVersion is the format of the events that API Gateway invokes a Lambda with, and of the responses it
+// expects back
+type ApiGatewayPayloadVersion string
+
+const (
+	// ApiGatewayPayloadV1 is the events.APIGatewayProxyRequest of a REST API
+	ApiGatewayPayloadV1 ApiGatewayPayloadVersion = "1.0"
+	// ApiGatewayPayloadV2 is the events.APIGatewayV2HTTPRequest of an HTTP API or a Lambda function URL
+	ApiGatewayPayloadV2 ApiGatewayPayloadVersion = "2.0"
+)
+
+// ApiGatewayRoute sends the requests that match RouteKey to a Lambda.  RouteKey is a method, or ANY, and a path, such
+// as "GET /orders/{id}" or "ANY /files/{proxy+}", in which {name} matches a path segment and a final {name+} the rest
+// of the path, or is "$default", which matches any request that no other route does
+type ApiGatewayRoute struct {
+	RouteKey string
+	Lambda   *LambdaDockerContainer
+}
+
+type ApiGatewayConfig struct {
+	// PayloadVersion defaults to ApiGatewayPayloadV2
+	PayloadVersion ApiGatewayPayloadVersion
+	// Port is the port on the host that the gateway listens on, which defaults to a free one
+	Port int
+	// BinaryMediaTypes are the content types, such as image/png or image/*, whose bodies a REST API base64-encodes;
+	// an HTTP API base64-encodes any body that isn't text
+	BinaryMediaTypes []string
+}
+
+// ApiGateway is an HTTP front end to Lambdas, as API Gateway and function URLs are in AWS.  It runs on the host,
+// converting the requests it receives into the events of its PayloadVersion, invoking the Lambda of the route that
+// they match, and converting the Lambda's response back
+type ApiGateway struct {
+	Config ApiGatewayConfig
+	Routes []ApiGatewayRoute
+
+	routes   []apiGatewayRoute
+	invoke   func(ctx context.Context, lambda *LambdaDockerContainer, event any) (LambdaResponse, error)
+	listener net.Listener
+	server   *http.Server
+	mu       sync.Mutex
+	errs     []error
+}
+
+// apiGatewayRoute is a parsed ApiGatewayRoute, whose method is empty for ANY and whose segments are nil for $default
+type apiGatewayRoute struct {
+	ApiGatewayRoute
+	method   string
+	segments []string
+}
+
+// WithApiGateway starts the gateway once the network is ready, and stops it before the containers are stopped, or are
+// rolled back because the network failed to start after the gateway did
+func (n NetworkOfDockerContainers) WithApiGateway(gateway *ApiGateway) NetworkOfDockerContainers {
+	return n.WithHooks(LifecycleHooks{
+		AfterReady: []LifecycleHook{gateway.Start},
+		BeforeStop: []LifecycleHook{gateway.Stop},
+		OnFailure: []FailureHook{func(ctx context.Context, _ error) {
+			_ = gateway.Stop(ctx)
+		}},
+	})
+}
+
+// Start listens for requests on Port, or a free port, until Stop is called
+func (g *ApiGateway) Start(context.Context) error {
+	if err := g.parseRoutes(); err != nil {
+		return err
+	}
+	if g.invoke == nil {
+		g.invoke = func(ctx context.Context, lambda *LambdaDockerContainer, event any) (LambdaResponse, error) {
+			return lambda.Invoke(ctx, event)
+		}
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", g.Config.Port))
+	if err != nil {
+		return fmt.Errorf("starting API Gateway: %w", err)
+	}
+	server := &http.Server{Handler: g}
+	g.listener = listener
+	g.server = server
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return nil
+}
+
+// Stop stops listening, waiting for any requests in progress to finish
+func (g *ApiGateway) Stop(ctx context.Context) error {
+	if g.server == nil {
+		return nil
+	}
+	if err := g.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("stopping API Gateway: %w", err)
+	}
+	g.server = nil
+	g.listener = nil
+	return nil
+}
+
+// Url is the base URL of the gateway on the host, such as http://127.0.0.1:54321, or an empty string if the gateway
+// isn't listening
+func (g *ApiGateway) Url() string {
+	if g.listener == nil {
+		return ""
+	}
+	return "http://" + g.listener.Addr().String()
+}
+
+// Errors returns the errors met invoking the Lambdas, such as their returning function errors or responses that
+// API Gateway can't use, for which it answers 502 Bad Gateway
+func (g *ApiGateway) Errors() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]error{}, g.errs...)
+}
+
+func (g *ApiGateway) failed(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errs = append(g.errs, err)
+}
+
+func (g *ApiGateway) payloadVersion() ApiGatewayPayloadVersion {
+	if g.Config.PayloadVersion == "" {
+		return ApiGatewayPayloadV2
+	}
+	return g.Config.PayloadVersion
+}
+
+func (g *ApiGateway) parseRoutes() error {
+	if version := g.payloadVersion(); version != ApiGatewayPayloadV1 && version != ApiGatewayPayloadV2 {
+		return fmt.Errorf("unsupported API Gateway payload version %s", version)
+	}
+	g.routes = nil
+	for _, route := range g.Routes {
+		if route.Lambda == nil {
+			return fmt.Errorf("API Gateway route %q has no Lambda", route.RouteKey)
+		}
+		if route.RouteKey == "$default" {
+			g.routes = append(g.routes, apiGatewayRoute{ApiGatewayRoute: route})
+			continue
+		}
+		method, routePath, ok := strings.Cut(route.RouteKey, " ")
+		if !ok || !strings.HasPrefix(routePath, "/") {
+			return fmt.Errorf("invalid API Gateway route %q: it must be $default or a method and a path", route.RouteKey)
+		}
+		parsed := apiGatewayRoute{ApiGatewayRoute: route, method: strings.ToUpper(method), segments: strings.Split(strings.Trim(routePath, "/"), "/")}
+		if parsed.method == "ANY" {
+			parsed.method = ""
+		}
+		for i, segment := range parsed.segments {
+			if _, greedy, _ := pathVariable(segment); greedy && i != len(parsed.segments)-1 {
+				return fmt.Errorf("invalid API Gateway route %q: a greedy path variable must be last", route.RouteKey)
+			}
+		}
+		g.routes = append(g.routes, parsed)
+	}
+	return nil
+}
+
+// match returns the path parameters of the request for the route, and whether it matches
+func (r apiGatewayRoute) match(method string, requestPath string) (map[string]string, bool) {
+	if r.segments == nil {
+		return nil, true
+	}
+	if r.method != "" && r.method != method {
+		return nil, false
+	}
+	segments := strings.Split(strings.Trim(requestPath, "/"), "/")
+	params := map[string]string{}
+	for i, segment := range r.segments {
+		name, greedy, isVariable := pathVariable(segment)
+		switch {
+		case i >= len(segments) || segments[i] == "" && isVariable:
+			return nil, false
+		case greedy:
+			params[name] = strings.Join(segments[i:], "/")
+			return params, true
+		case isVariable:
+			params[name] = segments[i]
+		case segment != segments[i]:
+			return nil, false
+		}
+	}
+	return params, len(segments) == len(r.segments)
+}
+
+// pathVariable returns the name of the path variable in a segment of a route, such as {id}, or {proxy+} if it is
+// greedy
+func pathVariable(segment string) (name string, greedy bool, ok bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false, false
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+	name, greedy = strings.CutSuffix(name, "+")
+	return name, greedy, true
+}
+
+// moreSpecificThan orders routes as API Gateway prefers them: any route before $default, routes without a greedy
+// path variable before those with one, more literal path segments before fewer, and a method before ANY
+func (r apiGatewayRoute) moreSpecificThan(other apiGatewayRoute) bool {
+	if (r.segments == nil) != (other.segments == nil) {
+		return other.segments == nil
+	}
+	if r.greedy() != other.greedy() {
+		return !r.greedy()
+	}
+	if r.literalSegments() != other.literalSegments() {
+		return r.literalSegments() > other.literalSegments()
+	}
+	return r.method != "" && other.method == ""
+}
+
+func (r apiGatewayRoute) greedy() bool {
+	if len(r.segments) == 0 {
+		return false
+	}
+	_, greedy, _ := pathVariable(r.segments[len(r.segments)-1])
+	return greedy
+}
+
+func (r apiGatewayRoute) literalSegments() int {
+	count := 0
+	for _, segment := range r.segments {
+		if _, _, isVariable := pathVariable(segment); !isVariable {
+			count++
+		}
+	}
+	return count
+}
+
+// route finds the most specific route that the request matches
+func (g *ApiGateway) route(req *http.Request) (apiGatewayRoute, map[string]string, bool) {
+	var best apiGatewayRoute
+	var bestParams map[string]string
+	found := false
+	for _, route := range g.routes {
+		params, ok := route.match(req.Method, req.URL.Path)
+		if ok && (!found || route.moreSpecificThan(best)) {
+			best, bestParams, found = route, params, true
+		}
+	}
+	return best, bestParams, found
+}
+
+func (g *ApiGateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route, params, ok := g.route(req)
+	if !ok && g.payloadVersion() == ApiGatewayPayloadV1 {
+		writeApiGatewayMessage(w, http.StatusForbidden, "Missing Authentication Token")
+		return
+	}
+	if !ok {
+		writeApiGatewayMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeApiGatewayMessage(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	var event any
+	if g.payloadVersion() == ApiGatewayPayloadV1 {
+		event = g.v1Request(req, route, params, body)
+	} else {
+		event = g.v2Request(req, route, params, body)
+	}
+	response, err := g.invoke(req.Context(), route.Lambda, event)
+	if err == nil {
+		if g.payloadVersion() == ApiGatewayPayloadV1 {
+			err = writeApiGatewayV1Response(w, response.Payload)
+		} else {
+			err = writeApiGatewayV2Response(w, response.Payload)
+		}
+	}
+	if err != nil {
+		g.failed(fmt.Errorf("%s %s: invoking Lambda %s: %w", req.Method, req.URL.Path, route.Lambda.FunctionName(), err))
+		writeApiGatewayMessage(w, http.StatusBadGateway, "Internal server error")
+	}
+}
+
+func (g *ApiGateway) v1Request(req *http.Request, route apiGatewayRoute, params map[string]string, body []byte) events.APIGatewayProxyRequest {
+	request := NewApiGatewayV1Request(req.Method, req.URL.Path)
+	if route.segments != nil {
+		_, routePath, _ := strings.Cut(route.RouteKey, " ")
+		request = request.WithResource(routePath)
+	}
+	request = request.WithHeader("Host", req.Host)
+	for _, name := range sortedKeys(req.Header) {
+		for _, value := range req.Header[name] {
+			request = request.WithHeader(name, value)
+		}
+	}
+	query := req.URL.Query()
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			request = request.WithQueryParameter(name, value)
+		}
+	}
+	for _, name := range sortedKeys(params) {
+		request = request.WithPathParameter(name, params[name])
+	}
+	if len(body) > 0 {
+		if g.isBinaryMediaType(req.Header.Get("Content-Type")) {
+			request = request.WithBinaryBody(body)
+		} else {
+			request = request.WithBody(string(body))
+		}
+	}
+	return request.Build()
+}
+
+func (g *ApiGateway) v2Request(req *http.Request, route apiGatewayRoute, params map[string]string, body []byte) events.APIGatewayV2HTTPRequest {
+	request := NewApiGatewayV2Request(req.Method, req.URL.Path).WithRouteKey(route.RouteKey)
+	request = request.WithHeader("Host", req.Host)
+	for _, name := range sortedKeys(req.Header) {
+		for _, value := range req.Header[name] {
+			if strings.EqualFold(name, "Cookie") {
+				// an HTTP API passes cookies separately from the headers
+				for _, cookie := range strings.Split(value, ";") {
+					request = request.WithCookie(strings.TrimSpace(cookie))
+				}
+				continue
+			}
+			request = request.WithHeader(name, value)
+		}
+	}
+	query := req.URL.Query()
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			request = request.WithQueryParameter(name, value)
+		}
+	}
+	for _, name := range sortedKeys(params) {
+		request = request.WithPathParameter(name, params[name])
+	}
+	if len(body) > 0 {
+		if isTextMediaType(req.Header.Get("Content-Type")) {
+			request = request.WithBody(string(body))
+		} else {
+			request = request.WithBinaryBody(body)
+		}
+	}
+	return request.Build()
+}
+
+// isBinaryMediaType is whether a REST API base64-encodes bodies of the content type
+func (g *ApiGateway) isBinaryMediaType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, binaryMediaType := range g.Config.BinaryMediaTypes {
+		if matched, _ := path.Match(binaryMediaType, mediaType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextMediaType is whether an HTTP API passes bodies of the content type as they are, rather than base64-encoding
+// them
+func isTextMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType == ""
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		slices.Contains([]string{"application/json", "application/xml", "application/javascript", "application/x-www-form-urlencoded"}, mediaType) ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+func writeApiGatewayV1Response(w http.ResponseWriter, payload []byte) error {
+	var response events.APIGatewayProxyResponse
+	if err := json.Unmarshal(payload, &response); err != nil || response.StatusCode == 0 {
+		return fmt.Errorf("malformed Lambda proxy response: %s", payload)
+	}
+	body, err := apiGatewayResponseBody(response.Body, response.IsBase64Encoded)
+	if err != nil {
+		return err
+	}
+	writeApiGatewayResponse(w, response.StatusCode, response.Headers, response.MultiValueHeaders, body)
+	return nil
+}
+
+// writeApiGatewayV2Response writes the events.APIGatewayV2HTTPResponse in payload or, if the function returned
+// something without a status code, the payload itself as JSON, as an HTTP API does
+func writeApiGatewayV2Response(w http.ResponseWriter, payload []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil || fields["statusCode"] == nil {
+		writeApiGatewayResponse(w, http.StatusOK, map[string]string{"Content-Type": "application/json"}, nil, payload)
+		return nil
+	}
+	var response events.APIGatewayV2HTTPResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return fmt.Errorf("malformed Lambda response: %s", payload)
+	}
+	body, err := apiGatewayResponseBody(response.Body, response.IsBase64Encoded)
+	if err != nil {
+		return err
+	}
+	multiValueHeaders := response.MultiValueHeaders
+	if len(response.Cookies) > 0 {
+		multiValueHeaders = withEntry(multiValueHeaders, "Set-Cookie", response.Cookies)
+	}
+	writeApiGatewayResponse(w, response.StatusCode, response.Headers, multiValueHeaders, body)
+	return nil
+}
+
+func apiGatewayResponseBody(body string, isBase64Encoded bool) ([]byte, error) {
+	if !isBase64Encoded {
+		return []byte(body), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 body of Lambda response: %w", err)
+	}
+	return content, nil
+}
+
+// writeApiGatewayResponse writes a response, with the values of multiValueHeaders replacing those of headers
+func writeApiGatewayResponse(w http.ResponseWriter, statusCode int, headers map[string]string, multiValueHeaders map[string][]string, body []byte) {
+	for name, value := range headers {
+		w.Header().Set(name, value)
+	}
+	for name, values := range multiValueHeaders {
+		w.Header().Del(name)
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// writeApiGatewayMessage writes an error of API Gateway's own, in the form that it writes them
+func writeApiGatewayMessage(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// startApiGateway starts the gateway with the Lambdas invoked by respond, recording the events they are invoked with
+func startApiGateway(t *testing.T, gateway *ApiGateway, respond func(lambda *LambdaDockerContainer, event any) (LambdaResponse, error)) *[]any {
+	var invocations []any
+	gateway.invoke = func(_ context.Context, lambda *LambdaDockerContainer, event any) (LambdaResponse, error) {
+		invocations = append(invocations, event)
+		return respond(lambda, event)
+	}
+	assert.NoError(t, gateway.Start(context.Background()))
+	t.Cleanup(func() {
+		assert.NoError(t, gateway.Stop(context.Background()))
+	})
+	return &invocations
+}
+
+func respondWith(response any) func(*LambdaDockerContainer, any) (LambdaResponse, error) {
+	return func(*LambdaDockerContainer, any) (LambdaResponse, error) {
+		payload, _ := json.Marshal(response)
+		return LambdaResponse{StatusCode: http.StatusOK, Payload: payload}, nil
+	}
+}
+
+func TestApiGateway_ConvertsRequestToHttpApiEventAndResponseBack(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "GET /orders/{id}", Lambda: &LambdaDockerContainer{}}}}
+	invocations := startApiGateway(t, gateway, respondWith(events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusCreated,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Cookies:    []string{"session=abc", "theme=dark"},
+		Body:       `{"id": "42"}`,
+	}))
+	req, _ := http.NewRequest(http.MethodGet, gateway.Url()+"/orders/42?expand=items&expand=customer", nil)
+	req.Header.Add("Cookie", "tracking=1; locale=en")
+	req.Header.Add("X-Request-Id", "abc-123")
+
+	res, err := http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"session=abc", "theme=dark"}, res.Header.Values("Set-Cookie"))
+	assert.Equal(t, `{"id": "42"}`, string(body))
+	event := (*invocations)[0].(events.APIGatewayV2HTTPRequest)
+	assert.Equal(t, "GET /orders/{id}", event.RouteKey)
+	assert.Equal(t, "/orders/42", event.RawPath)
+	assert.Equal(t, http.MethodGet, event.RequestContext.HTTP.Method)
+	assert.Equal(t, map[string]string{"id": "42"}, event.PathParameters)
+	assert.Equal(t, "items,customer", event.QueryStringParameters["expand"])
+	assert.Equal(t, "abc-123", event.Headers["x-request-id"])
+	assert.Equal(t, []string{"tracking=1", "locale=en"}, event.Cookies)
+	assert.NotContains(t, event.Headers, "cookie")
+}
+
+func TestApiGateway_ConvertsRequestToRestApiEventWithBinaryBody(t *testing.T) {
+	gateway := &ApiGateway{
+		Config: ApiGatewayConfig{PayloadVersion: ApiGatewayPayloadV1, BinaryMediaTypes: []string{"image/*"}},
+		Routes: []ApiGatewayRoute{{RouteKey: "ANY /images/{proxy+}", Lambda: &LambdaDockerContainer{}}},
+	}
+	invocations := startApiGateway(t, gateway, respondWith(events.APIGatewayProxyResponse{
+		StatusCode:        http.StatusOK,
+		MultiValueHeaders: map[string][]string{"Cache-Control": {"no-cache", "no-store"}},
+		Body:              base64.StdEncoding.EncodeToString([]byte{0x89, 0x50, 0x4e, 0x47}),
+		IsBase64Encoded:   true,
+	}))
+
+	res, err := http.Post(gateway.Url()+"/images/thumbnails/cat.png?size=small&size=large", "image/png", strings.NewReader("\x89PNG"))
+
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"no-cache", "no-store"}, res.Header.Values("Cache-Control"))
+	assert.Equal(t, []byte{0x89, 0x50, 0x4e, 0x47}, body)
+	event := (*invocations)[0].(events.APIGatewayProxyRequest)
+	assert.Equal(t, http.MethodPost, event.HTTPMethod)
+	assert.Equal(t, "/images/{proxy+}", event.Resource)
+	assert.Equal(t, "/images/thumbnails/cat.png", event.Path)
+	assert.Equal(t, map[string]string{"proxy": "thumbnails/cat.png"}, event.PathParameters)
+	assert.Equal(t, []string{"small", "large"}, event.MultiValueQueryStringParameters["size"])
+	assert.Equal(t, "image/png", event.Headers["Content-Type"])
+	assert.True(t, event.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("\x89PNG")), event.Body)
+}
+
+func TestApiGateway_PassesTextBodiesAsTheyAre(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "POST /orders", Lambda: &LambdaDockerContainer{}}}}
+	invocations := startApiGateway(t, gateway, respondWith(events.APIGatewayV2HTTPResponse{StatusCode: http.StatusAccepted}))
+
+	_, err := http.Post(gateway.Url()+"/orders", "application/json; charset=utf-8", strings.NewReader(`{"item": "book"}`))
+
+	assert.NoError(t, err)
+	event := (*invocations)[0].(events.APIGatewayV2HTTPRequest)
+	assert.False(t, event.IsBase64Encoded)
+	assert.Equal(t, `{"item": "book"}`, event.Body)
+}
+
+func TestApiGateway_ReturnsHttpApiResponseWithoutStatusCodeAsJson(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "$default", Lambda: &LambdaDockerContainer{}}}}
+	startApiGateway(t, gateway, respondWith(map[string]string{"status": "ok"}))
+
+	res, err := http.Get(gateway.Url() + "/health")
+
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"status": "ok"}`, string(body))
+}
+
+func TestApiGateway_SendsRequestsToMostSpecificRoute(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{
+		{RouteKey: "$default", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "fallback"}}},
+		{RouteKey: "ANY /{proxy+}", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "proxy"}}},
+		{RouteKey: "ANY /orders/{id}", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "any-order"}}},
+		{RouteKey: "GET /orders/{id}", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "get-order"}}},
+		{RouteKey: "GET /orders/latest", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "latest-order"}}},
+		{RouteKey: "GET /", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "home"}}},
+	}}
+	startApiGateway(t, gateway, func(lambda *LambdaDockerContainer, _ any) (LambdaResponse, error) {
+		return respondWith(events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK, Body: lambda.FunctionName()})(lambda, nil)
+	})
+
+	for _, test := range []struct {
+		method   string
+		path     string
+		function string
+	}{
+		{http.MethodGet, "/orders/latest", "latest-order"},
+		{http.MethodGet, "/orders/42", "get-order"},
+		{http.MethodDelete, "/orders/42", "any-order"},
+		{http.MethodGet, "/orders/42/items", "proxy"},
+		{http.MethodGet, "/", "home"},
+		{http.MethodPost, "/", "fallback"},
+	} {
+		req, _ := http.NewRequest(test.method, gateway.Url()+test.path, nil)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, test.function, string(body), "%s %s", test.method, test.path)
+	}
+}
+
+func TestApiGateway_AnswersLikeApiGatewayWhenThereIsNoRouteOrTheLambdaFails(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "GET /orders/{id}", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "orders"}}}}}
+	startApiGateway(t, gateway, func(*LambdaDockerContainer, any) (LambdaResponse, error) {
+		return LambdaResponse{}, &LambdaFunctionError{ErrorType: "errorString", ErrorMessage: "no such order"}
+	})
+
+	notFound, err := http.Get(gateway.Url() + "/customers/1")
+	assert.NoError(t, err)
+	notFoundBody, _ := io.ReadAll(notFound.Body)
+	failed, err := http.Get(gateway.Url() + "/orders/1")
+	assert.NoError(t, err)
+	failedBody, _ := io.ReadAll(failed.Body)
+
+	assert.Equal(t, http.StatusNotFound, notFound.StatusCode)
+	assert.JSONEq(t, `{"message": "Not Found"}`, string(notFoundBody))
+	assert.Equal(t, http.StatusBadGateway, failed.StatusCode)
+	assert.JSONEq(t, `{"message": "Internal server error"}`, string(failedBody))
+	assert.Len(t, gateway.Errors(), 1)
+	assert.EqualError(t, gateway.Errors()[0], "GET /orders/1: invoking Lambda orders: Lambda function error: errorString: no such order")
+}
+
+func TestApiGateway_AnswersUnmatchedRequestsAsEachPayloadVersionDoes(t *testing.T) {
+	for _, test := range []struct {
+		version    ApiGatewayPayloadVersion
+		statusCode int
+		body       string
+	}{
+		{ApiGatewayPayloadV1, http.StatusForbidden, `{"message": "Missing Authentication Token"}`},
+		{ApiGatewayPayloadV2, http.StatusNotFound, `{"message": "Not Found"}`},
+	} {
+		gateway := &ApiGateway{
+			Config: ApiGatewayConfig{PayloadVersion: test.version},
+			Routes: []ApiGatewayRoute{{RouteKey: "GET /orders/{id}", Lambda: &LambdaDockerContainer{}}},
+		}
+		startApiGateway(t, gateway, respondWith(events.APIGatewayProxyResponse{StatusCode: http.StatusOK}))
+
+		res, err := http.Get(gateway.Url() + "/customers/1")
+
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, test.statusCode, res.StatusCode, test.version)
+		assert.JSONEq(t, test.body, string(body), test.version)
+	}
+}
+
+func TestApiGateway_HasNoUrlUnlessListening(t *testing.T) {
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "/orders", Lambda: &LambdaDockerContainer{}}}}
+	assert.Empty(t, gateway.Url())
+
+	assert.Error(t, gateway.Start(context.Background()))
+	assert.Empty(t, gateway.Url())
+
+	gateway.Routes[0].RouteKey = "GET /orders"
+	assert.NoError(t, gateway.Start(context.Background()))
+	assert.NotEmpty(t, gateway.Url())
+	assert.NoError(t, gateway.Stop(context.Background()))
+	assert.Empty(t, gateway.Url())
+}
+
+func TestApiGateway_StopsWhenNetworkFailsToStartAfterIt(t *testing.T) {
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders"}}
+	gateway := &ApiGateway{Routes: []ApiGatewayRoute{{RouteKey: "GET /orders", Lambda: lambdaContainer}}}
+	network := fakeNetwork(&FakeContainerProvider{}, lambdaContainer).
+		WithApiGateway(gateway).
+		WithHooks(LifecycleHooks{AfterReady: []LifecycleHook{func(context.Context) error {
+			return errors.New("table orders already exists")
+		}}})
+
+	assert.ErrorContains(t, network.StartWithDelay(0), "table orders already exists")
+
+	assert.Empty(t, gateway.Url())
+}
+
+func TestApiGateway_RejectsInvalidRoutes(t *testing.T) {
+	for _, test := range []struct {
+		route ApiGatewayRoute
+		err   string
+	}{
+		{ApiGatewayRoute{RouteKey: "/orders", Lambda: &LambdaDockerContainer{}}, `invalid API Gateway route "/orders": it must be $default or a method and a path`},
+		{ApiGatewayRoute{RouteKey: "GET /{proxy+}/items", Lambda: &LambdaDockerContainer{}}, `invalid API Gateway route "GET /{proxy+}/items": a greedy path variable must be last`},
+		{ApiGatewayRoute{RouteKey: "GET /orders"}, `API Gateway route "GET /orders" has no Lambda`},
+	} {
+		gateway := &ApiGateway{Routes: []ApiGatewayRoute{test.route}}
+
+		assert.EqualError(t, gateway.Start(context.Background()), test.err)
+	}
+}
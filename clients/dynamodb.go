@@ -15,6 +15,9 @@ type IDynamoDbClient interface {
 	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
 }
 
 type DynamoDbClient struct {
@@ -66,3 +69,36 @@ func (c DynamoDbClient) PutObject(table string, object interface{}) error {
 	}
 	return nil
 }
+
+func (c DynamoDbClient) GetTableNames() ([]string, error) {
+	var tableNames []string
+	input := &dynamodb.ListTablesInput{}
+	for {
+		listTablesOutput, err := c.handle.ListTables(context.Background(), input)
+		if err != nil {
+			return nil, fmt.Errorf("listing tables: %v", err)
+		}
+		tableNames = append(tableNames, listTablesOutput.TableNames...)
+		if listTablesOutput.LastEvaluatedTableName == nil {
+			return tableNames, nil
+		}
+		input = &dynamodb.ListTablesInput{ExclusiveStartTableName: listTablesOutput.LastEvaluatedTableName}
+	}
+}
+
+func (c DynamoDbClient) DescribeTable(table string) (*types.TableDescription, error) {
+	describeTableOutput, err := c.handle.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing table: %v", err)
+	}
+	return describeTableOutput.Table, nil
+}
+
+func (c DynamoDbClient) DeleteTable(table string) error {
+	_, err := c.handle.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{
+		TableName: aws.String(table),
+	})
+	return err
+}
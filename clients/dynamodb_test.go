@@ -30,6 +30,21 @@ func (m *MockDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutIt
 	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
 }
 
+func (m *MockDynamoDBClient) ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.ListTablesOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.DescribeTableOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBClient) DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodb.DeleteTableOutput), args.Error(1)
+}
+
 func TestDynamoDbClient_GetItemsInTable(t *testing.T) {
 	mockClient := new(MockDynamoDBClient)
 	dynamoDbClient := DynamoDbClient{handle: mockClient}
@@ -136,3 +151,69 @@ func TestDynamoDbClient_PutObject_Error(t *testing.T) {
 	assert.Error(t, err)
 	mockClient.AssertExpectations(t)
 }
+
+func TestDynamoDbClient_GetTableNames(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	dynamoDbClient := DynamoDbClient{handle: mockClient}
+
+	mockClient.On("ListTables", mock.Anything, &dynamodb.ListTablesInput{}).Return(&dynamodb.ListTablesOutput{TableNames: []string{"table1"}, LastEvaluatedTableName: aws.String("table1")}, nil)
+	mockClient.On("ListTables", mock.Anything, &dynamodb.ListTablesInput{ExclusiveStartTableName: aws.String("table1")}).Return(&dynamodb.ListTablesOutput{TableNames: []string{"table2"}}, nil)
+
+	result, err := dynamoDbClient.GetTableNames()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"table1", "table2"}, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbClient_GetTableNames_Error(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	dynamoDbClient := DynamoDbClient{handle: mockClient}
+
+	mockClient.On("ListTables", mock.Anything, &dynamodb.ListTablesInput{}).Return(&dynamodb.ListTablesOutput{}, errors.New("error"))
+
+	result, err := dynamoDbClient.GetTableNames()
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbClient_DescribeTable(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	dynamoDbClient := DynamoDbClient{handle: mockClient}
+	description := &types.TableDescription{TableName: aws.String("testTable")}
+
+	mockClient.On("DescribeTable", mock.Anything, &dynamodb.DescribeTableInput{TableName: aws.String("testTable")}).Return(&dynamodb.DescribeTableOutput{Table: description}, nil)
+
+	result, err := dynamoDbClient.DescribeTable("testTable")
+
+	assert.NoError(t, err)
+	assert.Equal(t, description, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbClient_DescribeTable_Error(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	dynamoDbClient := DynamoDbClient{handle: mockClient}
+
+	mockClient.On("DescribeTable", mock.Anything, &dynamodb.DescribeTableInput{TableName: aws.String("testTable")}).Return(&dynamodb.DescribeTableOutput{}, errors.New("error"))
+
+	result, err := dynamoDbClient.DescribeTable("testTable")
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbClient_DeleteTable(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	dynamoDbClient := DynamoDbClient{handle: mockClient}
+
+	mockClient.On("DeleteTable", mock.Anything, &dynamodb.DeleteTableInput{TableName: aws.String("testTable")}).Return(&dynamodb.DeleteTableOutput{}, nil)
+
+	err := dynamoDbClient.DeleteTable("testTable")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,100 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"os"
+)
+
+type IDynamoDbStreamsClient interface {
+	ListStreams(ctx context.Context, params *dynamodbstreams.ListStreamsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.ListStreamsOutput, error)
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+type DynamoDbStreamsClient struct {
+	handle IDynamoDbStreamsClient
+}
+
+func (c DynamoDbStreamsClient) New(hostname string, port int) (DynamoDbStreamsClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(os.Getenv("AWS_REGION")))
+	if err != nil {
+		return DynamoDbStreamsClient{}, fmt.Errorf("loading config: %v", err)
+	}
+	c.handle = dynamodbstreams.NewFromConfig(cfg, func(o *dynamodbstreams.Options) {
+		o.BaseEndpoint = aws.String(fmt.Sprintf("http://%s:%d", hostname, port))
+	})
+	return c, nil
+}
+
+// StreamArn returns the ARN of the latest stream of the table, or an empty string if the table has no stream
+func (c DynamoDbStreamsClient) StreamArn(ctx context.Context, table string) (string, error) {
+	listStreamsOutput, err := c.handle.ListStreams(ctx, &dynamodbstreams.ListStreamsInput{TableName: aws.String(table)})
+	if err != nil {
+		return "", fmt.Errorf("listing streams: %w", err)
+	}
+	if len(listStreamsOutput.Streams) == 0 {
+		return "", nil
+	}
+	return aws.ToString(listStreamsOutput.Streams[len(listStreamsOutput.Streams)-1].StreamArn), nil
+}
+
+func (c DynamoDbStreamsClient) Shards(ctx context.Context, streamArn string) ([]types.Shard, error) {
+	var shards []types.Shard
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(streamArn)}
+	for {
+		describeStreamOutput, err := c.handle.DescribeStream(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("describing stream: %w", err)
+		}
+		shards = append(shards, describeStreamOutput.StreamDescription.Shards...)
+		if describeStreamOutput.StreamDescription.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+		input = &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(streamArn), ExclusiveStartShardId: describeStreamOutput.StreamDescription.LastEvaluatedShardId}
+	}
+}
+
+func (c DynamoDbStreamsClient) ShardIterator(ctx context.Context, streamArn string, shardId string, iteratorType types.ShardIteratorType) (string, error) {
+	shardIteratorOutput, err := c.handle.GetShardIterator(ctx, &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamArn),
+		ShardId:           aws.String(shardId),
+		ShardIteratorType: iteratorType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting shard iterator: %w", err)
+	}
+	return aws.ToString(shardIteratorOutput.ShardIterator), nil
+}
+
+// ShardIteratorAfter returns an iterator that reads the shard from the record after the one with the sequence number
+func (c DynamoDbStreamsClient) ShardIteratorAfter(ctx context.Context, streamArn string, shardId string, sequenceNumber string) (string, error) {
+	shardIteratorOutput, err := c.handle.GetShardIterator(ctx, &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamArn),
+		ShardId:           aws.String(shardId),
+		ShardIteratorType: types.ShardIteratorTypeAfterSequenceNumber,
+		SequenceNumber:    aws.String(sequenceNumber),
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting shard iterator: %w", err)
+	}
+	return aws.ToString(shardIteratorOutput.ShardIterator), nil
+}
+
+// GetRecords returns up to limit records from the shard iterator along with the iterator to read the next from, which
+// is empty once the shard is closed and has been read to the end
+func (c DynamoDbStreamsClient) GetRecords(ctx context.Context, shardIterator string, limit int32) ([]types.Record, string, error) {
+	getRecordsOutput, err := c.handle.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+		ShardIterator: aws.String(shardIterator),
+		Limit:         aws.Int32(limit),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("getting records: %w", err)
+	}
+	return getRecordsOutput.Records, aws.ToString(getRecordsOutput.NextShardIterator), nil
+}
@@ -0,0 +1,145 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type MockDynamoDBStreamsClient struct {
+	mock.Mock
+}
+
+func (m *MockDynamoDBStreamsClient) ListStreams(ctx context.Context, params *dynamodbstreams.ListStreamsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.ListStreamsOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodbstreams.ListStreamsOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBStreamsClient) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodbstreams.DescribeStreamOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBStreamsClient) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodbstreams.GetShardIteratorOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBStreamsClient) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*dynamodbstreams.GetRecordsOutput), args.Error(1)
+}
+
+func TestDynamoDbStreamsClient_StreamArn(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("ListStreams", mock.Anything, &dynamodbstreams.ListStreamsInput{TableName: aws.String("orders")}).Return(&dynamodbstreams.ListStreamsOutput{
+		Streams: []types.Stream{{StreamArn: aws.String("old-stream")}, {StreamArn: aws.String("new-stream")}},
+	}, nil)
+
+	streamArn, err := streamsClient.StreamArn(context.Background(), "orders")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new-stream", streamArn)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbStreamsClient_StreamArn_NoStream(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("ListStreams", mock.Anything, mock.Anything).Return(&dynamodbstreams.ListStreamsOutput{}, nil)
+
+	streamArn, err := streamsClient.StreamArn(context.Background(), "orders")
+
+	assert.NoError(t, err)
+	assert.Empty(t, streamArn)
+}
+
+func TestDynamoDbStreamsClient_Shards(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("DescribeStream", mock.Anything, &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String("stream")}).Return(&dynamodbstreams.DescribeStreamOutput{
+		StreamDescription: &types.StreamDescription{Shards: []types.Shard{{ShardId: aws.String("shard-1")}}, LastEvaluatedShardId: aws.String("shard-1")},
+	}, nil)
+	mockClient.On("DescribeStream", mock.Anything, &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String("stream"), ExclusiveStartShardId: aws.String("shard-1")}).Return(&dynamodbstreams.DescribeStreamOutput{
+		StreamDescription: &types.StreamDescription{Shards: []types.Shard{{ShardId: aws.String("shard-2")}}},
+	}, nil)
+
+	shards, err := streamsClient.Shards(context.Background(), "stream")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Shard{{ShardId: aws.String("shard-1")}, {ShardId: aws.String("shard-2")}}, shards)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbStreamsClient_ShardIterator(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("GetShardIterator", mock.Anything, &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String("stream"),
+		ShardId:           aws.String("shard-1"),
+		ShardIteratorType: types.ShardIteratorTypeTrimHorizon,
+	}).Return(&dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String("iterator")}, nil)
+
+	shardIterator, err := streamsClient.ShardIterator(context.Background(), "stream", "shard-1", types.ShardIteratorTypeTrimHorizon)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "iterator", shardIterator)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbStreamsClient_ShardIteratorAfter(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("GetShardIterator", mock.Anything, &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String("stream"),
+		ShardId:           aws.String("shard-1"),
+		ShardIteratorType: types.ShardIteratorTypeAfterSequenceNumber,
+		SequenceNumber:    aws.String("000000000000000000042"),
+	}).Return(&dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String("iterator")}, nil)
+
+	shardIterator, err := streamsClient.ShardIteratorAfter(context.Background(), "stream", "shard-1", "000000000000000000042")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "iterator", shardIterator)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbStreamsClient_GetRecords(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+	records := []types.Record{{EventID: aws.String("event-1")}}
+
+	mockClient.On("GetRecords", mock.Anything, &dynamodbstreams.GetRecordsInput{ShardIterator: aws.String("iterator"), Limit: aws.Int32(100)}).Return(&dynamodbstreams.GetRecordsOutput{
+		Records:           records,
+		NextShardIterator: aws.String("next-iterator"),
+	}, nil)
+
+	result, nextShardIterator, err := streamsClient.GetRecords(context.Background(), "iterator", 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, records, result)
+	assert.Equal(t, "next-iterator", nextShardIterator)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDynamoDbStreamsClient_GetRecords_Error(t *testing.T) {
+	mockClient := new(MockDynamoDBStreamsClient)
+	streamsClient := DynamoDbStreamsClient{handle: mockClient}
+
+	mockClient.On("GetRecords", mock.Anything, mock.Anything).Return(&dynamodbstreams.GetRecordsOutput{}, errors.New("expired iterator"))
+
+	_, _, err := streamsClient.GetRecords(context.Background(), "iterator", 100)
+
+	assert.EqualError(t, err, "getting records: expired iterator")
+}
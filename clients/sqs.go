@@ -8,11 +8,15 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"os"
+	"time"
 )
 
 type ISqsClient interface {
 	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error)
+	PurgeQueue(ctx context.Context, params *sqs.PurgeQueueInput, optFns ...func(*sqs.Options)) (*sqs.PurgeQueueOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
 }
 
 type SqsClient struct {
@@ -53,3 +57,59 @@ func (s SqsClient) GetMessagesFrom(queue string) ([]types.Message, error) {
 	}
 	return receiveMessageOutput.Messages, nil
 }
+
+func (s SqsClient) PurgeAllQueues() error {
+	listQueuesOutput, err := s.handle.ListQueues(context.Background(), &sqs.ListQueuesInput{})
+	if err != nil {
+		return fmt.Errorf("listing queues: %v", err)
+	}
+	for _, queueUrl := range listQueuesOutput.QueueUrls {
+		if _, err := s.handle.PurgeQueue(context.Background(), &sqs.PurgeQueueInput{QueueUrl: aws.String(queueUrl)}); err != nil {
+			return fmt.Errorf("purging queue %s: %v", queueUrl, err)
+		}
+	}
+	return nil
+}
+
+func (s SqsClient) QueueUrl(ctx context.Context, queue string) (string, error) {
+	queueUrlOutput, err := s.handle.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(queue)})
+	if err != nil {
+		return "", fmt.Errorf("getting queue url: %v", err)
+	}
+	return aws.ToString(queueUrlOutput.QueueUrl), nil
+}
+
+// ReceiveMessages receives up to maxNumberOfMessages messages, with all their attributes, waiting up to waitTime for
+// the first to arrive
+func (s SqsClient) ReceiveMessages(ctx context.Context, queueUrl string, maxNumberOfMessages int32, waitTime time.Duration) ([]types.Message, error) {
+	receiveMessageOutput, err := s.handle.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(queueUrl),
+		MaxNumberOfMessages:   maxNumberOfMessages,
+		WaitTimeSeconds:       int32(waitTime / time.Second),
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("receiving messages: %v", err)
+	}
+	return receiveMessageOutput.Messages, nil
+}
+
+// DeleteMessages deletes the messages from the queue, in batches of ten, which is the most SQS allows
+func (s SqsClient) DeleteMessages(ctx context.Context, queueUrl string, messages []types.Message) error {
+	for start := 0; start < len(messages); start += 10 {
+		var entries []types.DeleteMessageBatchRequestEntry
+		for _, message := range messages[start:min(start+10, len(messages))] {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{Id: message.MessageId, ReceiptHandle: message.ReceiptHandle})
+		}
+		deleteMessageBatchOutput, err := s.handle.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{QueueUrl: aws.String(queueUrl), Entries: entries})
+		if err != nil {
+			return fmt.Errorf("deleting messages: %v", err)
+		}
+		if len(deleteMessageBatchOutput.Failed) > 0 {
+			failed := deleteMessageBatchOutput.Failed[0]
+			return fmt.Errorf("deleting message %s: %s", aws.ToString(failed.Id), aws.ToString(failed.Message))
+		}
+	}
+	return nil
+}
@@ -3,11 +3,14 @@ package clients
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 type MockSQSClient struct {
@@ -24,6 +27,21 @@ func (m *MockSQSClient) ReceiveMessage(ctx context.Context, params *sqs.ReceiveM
 	return args.Get(0).(*sqs.ReceiveMessageOutput), args.Error(1)
 }
 
+func (m *MockSQSClient) ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*sqs.ListQueuesOutput), args.Error(1)
+}
+
+func (m *MockSQSClient) PurgeQueue(ctx context.Context, params *sqs.PurgeQueueInput, optFns ...func(*sqs.Options)) (*sqs.PurgeQueueOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*sqs.PurgeQueueOutput), args.Error(1)
+}
+
+func (m *MockSQSClient) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*sqs.DeleteMessageBatchOutput), args.Error(1)
+}
+
 func TestSqsClient_GetMessagesFrom(t *testing.T) {
 	mockClient := new(MockSQSClient)
 	sqsClient := SqsClient{handle: mockClient}
@@ -68,3 +86,89 @@ func TestSqsClient_GetMessagesFrom_ReceiveMessagesReturnsError(t *testing.T) {
 	assert.Nil(t, result)
 	mockClient.AssertExpectations(t)
 }
+
+func TestSqsClient_PurgeAllQueues(t *testing.T) {
+	mockClient := new(MockSQSClient)
+	sqsClient := SqsClient{handle: mockClient}
+	queueUrls := []string{"http://sqs:9324/queue/queue1", "http://sqs:9324/queue/queue2"}
+
+	mockClient.On("ListQueues", mock.Anything, &sqs.ListQueuesInput{}).Return(&sqs.ListQueuesOutput{QueueUrls: queueUrls}, nil)
+	mockClient.On("PurgeQueue", mock.Anything, &sqs.PurgeQueueInput{QueueUrl: &queueUrls[0]}).Return(&sqs.PurgeQueueOutput{}, nil)
+	mockClient.On("PurgeQueue", mock.Anything, &sqs.PurgeQueueInput{QueueUrl: &queueUrls[1]}).Return(&sqs.PurgeQueueOutput{}, nil)
+
+	err := sqsClient.PurgeAllQueues()
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSqsClient_PurgeAllQueues_PurgeQueueReturnsError(t *testing.T) {
+	mockClient := new(MockSQSClient)
+	sqsClient := SqsClient{handle: mockClient}
+	queueUrls := []string{"http://sqs:9324/queue/queue1"}
+
+	mockClient.On("ListQueues", mock.Anything, &sqs.ListQueuesInput{}).Return(&sqs.ListQueuesOutput{QueueUrls: queueUrls}, nil)
+	mockClient.On("PurgeQueue", mock.Anything, &sqs.PurgeQueueInput{QueueUrl: &queueUrls[0]}).Return(&sqs.PurgeQueueOutput{}, errors.New("error"))
+
+	err := sqsClient.PurgeAllQueues()
+
+	assert.Error(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSqsClient_ReceiveMessages(t *testing.T) {
+	mockClient := new(MockSQSClient)
+	sqsClient := SqsClient{handle: mockClient}
+	queueUrl := "http://sqs:9324/queue/queue1"
+	messages := []types.Message{{}}
+
+	mockClient.On("ReceiveMessage", mock.Anything, &sqs.ReceiveMessageInput{
+		QueueUrl:              &queueUrl,
+		MaxNumberOfMessages:   5,
+		WaitTimeSeconds:       1,
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	}).Return(&sqs.ReceiveMessageOutput{Messages: messages}, nil)
+
+	result, err := sqsClient.ReceiveMessages(context.Background(), queueUrl, 5, time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, messages, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSqsClient_DeleteMessages_DeletesInBatchesOfTen(t *testing.T) {
+	mockClient := new(MockSQSClient)
+	sqsClient := SqsClient{handle: mockClient}
+	queueUrl := "http://sqs:9324/queue/queue1"
+	var messages []types.Message
+	for i := 0; i < 12; i++ {
+		messages = append(messages, types.Message{MessageId: aws.String(fmt.Sprint(i)), ReceiptHandle: aws.String(fmt.Sprint("receipt-", i))})
+	}
+
+	mockClient.On("DeleteMessageBatch", mock.Anything, mock.MatchedBy(func(input *sqs.DeleteMessageBatchInput) bool {
+		return len(input.Entries) == 10
+	})).Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+	mockClient.On("DeleteMessageBatch", mock.Anything, mock.MatchedBy(func(input *sqs.DeleteMessageBatchInput) bool {
+		return len(input.Entries) == 2 && *input.Entries[1].ReceiptHandle == "receipt-11"
+	})).Return(&sqs.DeleteMessageBatchOutput{}, nil).Once()
+
+	err := sqsClient.DeleteMessages(context.Background(), queueUrl, messages)
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSqsClient_DeleteMessages_ReturnsFailures(t *testing.T) {
+	mockClient := new(MockSQSClient)
+	sqsClient := SqsClient{handle: mockClient}
+	queueUrl := "http://sqs:9324/queue/queue1"
+
+	mockClient.On("DeleteMessageBatch", mock.Anything, mock.Anything).Return(&sqs.DeleteMessageBatchOutput{
+		Failed: []types.BatchResultErrorEntry{{Id: aws.String("1"), Message: aws.String("receipt handle is invalid")}},
+	}, nil)
+
+	err := sqsClient.DeleteMessages(context.Background(), queueUrl, []types.Message{{MessageId: aws.String("1")}})
+
+	assert.EqualError(t, err, "deleting message 1: receipt handle is invalid")
+}
@@ -0,0 +1,39 @@
+// Command pull pre-pulls the images used by the library's built-in containers so that CI runs don't spend the first
+// scenario's timeout downloading them
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mikebharris/testcontainernetwork-go"
+)
+
+func main() {
+	policy := flag.String("policy", string(testcontainernetwork.PullIfMissing), "pull policy: always, if-missing or never")
+	archives := flag.String("archives", "", "directory of docker save archives to load missing images from")
+	flag.Parse()
+
+	pullPolicy, err := testcontainernetwork.ParsePullPolicy(*policy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	networkOfDockerContainers :=
+		testcontainernetwork.NetworkOfDockerContainers{}.
+			WithPullPolicy(pullPolicy).
+			WithImageArchives(*archives).
+			WithPullProgress(os.Stdout).
+			WithDockerContainer(&testcontainernetwork.DynamoDbDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.FlywayDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.LambdaDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.PostgresDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.SnsDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.SqsDockerContainer{}).
+			WithDockerContainer(&testcontainernetwork.WiremockDockerContainer{})
+	if err := networkOfDockerContainers.Pull(context.Background()); err != nil {
+		log.Fatalf("pulling images: %v", err)
+	}
+}
@@ -2,10 +2,12 @@ package testcontainernetwork
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/network"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"io"
 	"log"
 	"time"
 )
@@ -17,8 +19,42 @@ type StartableDockerContainer interface {
 }
 
 type DockerContainer struct {
-	testContainer       testcontainers.Container
+	testContainer       Container
 	internalServicePort int
+	hooks               LifecycleHooks
+	name                string
+	network             *NetworkOfDockerContainers
+	memberships         []networkMembership
+	copies              []directoryCopy
+	pendingCopies       []directoryCopy
+}
+
+// networkedDockerContainer is implemented by containers that promote DockerContainer, which are told the network they
+// are being started in, so that they use its provider and report the events within StartUsing that it can't see
+type networkedDockerContainer interface {
+	joinNetwork(network *NetworkOfDockerContainers)
+	containerName() string
+}
+
+func (c *DockerContainer) joinNetwork(network *NetworkOfDockerContainers) {
+	c.network = network
+}
+
+func (c *DockerContainer) containerName() string {
+	return c.name
+}
+
+func (c *DockerContainer) emit(event Event) {
+	if c.network != nil {
+		c.network.emit(event)
+	}
+}
+
+func (c *DockerContainer) containerProvider() ContainerProvider {
+	if c.network != nil {
+		return c.network.containerProvider()
+	}
+	return &dockerProvider{}
 }
 
 func (c *DockerContainer) MappedPort() int {
@@ -29,13 +65,80 @@ func (c *DockerContainer) MappedPort() int {
 	return mappedPort.Int()
 }
 
+// exec runs cmd in the container, returning its output as part of the error should it fail
+func (c *DockerContainer) exec(ctx context.Context, cmd ...string) error {
+	_, err := c.execOutput(ctx, cmd...)
+	return err
+}
+
+// execOutput runs cmd in the container and returns its output
+func (c *DockerContainer) execOutput(ctx context.Context, cmd ...string) (string, error) {
+	exitCode, output, err := c.testContainer.Exec(ctx, cmd, tcexec.Multiplexed())
+	if err != nil {
+		return "", fmt.Errorf("executing %s: %w", cmd[0], err)
+	}
+	out, _ := io.ReadAll(output)
+	if exitCode != 0 {
+		return "", fmt.Errorf("executing %s: exit code %d: %s", cmd[0], exitCode, out)
+	}
+	return string(out), nil
+}
+
+// createAndStart creates the container described by req, runs beforeStart, for example to copy files into it, and then
+// starts it
+func (c *DockerContainer) createAndStart(ctx context.Context, req testcontainers.ContainerRequest, beforeStart ...func(ctx context.Context) error) error {
+	c.name = req.Name
+	if err := c.joinNetworks(&req); err != nil {
+		return err
+	}
+	if c.network != nil && len(c.network.hostAccessPorts) > 0 {
+		req.HostAccessPorts = append(req.HostAccessPorts, c.network.hostAccessPorts...)
+	}
+	created := time.Now()
+	var err error
+	if c.testContainer, err = c.containerProvider().CreateContainer(ctx, req); err != nil {
+		return fmt.Errorf("creating container: %w", err)
+	}
+	c.emit(Event{Type: EventContainerCreated, Container: c.name, Image: req.Image, Duration: time.Since(created)})
+
+	if err := c.copyDirectories(ctx); err != nil {
+		return err
+	}
+
+	for _, f := range beforeStart {
+		if err := f(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := c.testContainer.Start(ctx); err != nil {
+		return fmt.Errorf("starting container: %w", err)
+	}
+	return nil
+}
+
 func (c *DockerContainer) Stop(ctx context.Context) error {
+	if c.testContainer == nil {
+		return nil
+	}
 	return c.testContainer.Terminate(ctx)
 }
 
 type NetworkOfDockerContainers struct {
 	dockerNetwork    *testcontainers.DockerNetwork
 	dockerContainers []StartableDockerContainer
+	pullPolicy       PullPolicy
+	pullProgress     io.Writer
+	imageArchives    string
+	hooks            LifecycleHooks
+	eventListeners   []EventListener
+	timings          []ContainerTiming
+	provider         ContainerProvider
+	attempted        []StartableDockerContainer
+	hostAccessPorts  []int
+	subNetworkNames  []string
+	subNetworks      map[string]*testcontainers.DockerNetwork
+	mountStrategy    MountStrategy
 }
 
 func (n NetworkOfDockerContainers) WithDockerContainer(dockerContainer StartableDockerContainer) NetworkOfDockerContainers {
@@ -47,31 +150,147 @@ func (n NetworkOfDockerContainers) WithDockerContainer(dockerContainer Startable
 // has side effects and thus this fits better with a functional programming paradigm
 func (n *NetworkOfDockerContainers) StartWithDelay(delay time.Duration) error {
 	ctx := context.Background()
+	if err := n.Pull(ctx); err != nil {
+		return n.failed(ctx, fmt.Errorf("pulling images: %s", err))
+	}
 	var err error
-	if n.dockerNetwork, err = network.New(ctx); err != nil {
-		return fmt.Errorf("creating network: %s", err)
+	if n.dockerNetwork, err = n.containerProvider().CreateNetwork(ctx); err != nil {
+		return n.failed(ctx, fmt.Errorf("creating network: %s", err))
+	}
+	if err := n.createSubNetworks(ctx); err != nil {
+		return n.failed(ctx, err)
+	}
+	if err := n.resolveMountStrategy(ctx); err != nil {
+		return n.failed(ctx, err)
 	}
+	if err := runHooks(ctx, n.hooks.BeforeStart); err != nil {
+		return n.failed(ctx, fmt.Errorf("running before start hooks: %s", err))
+	}
+	n.timings = nil
+	n.attempted = nil
 	for _, dockerContainer := range n.dockerContainers {
+		hooks := hooksOf(dockerContainer)
+		if networked, ok := dockerContainer.(networkedDockerContainer); ok {
+			networked.joinNetwork(n)
+		}
+		if err := runHooks(ctx, hooks.BeforeStart); err != nil {
+			return n.containerFailed(ctx, dockerContainer, fmt.Errorf("running container before start hooks: %s", err))
+		}
+		started := time.Now()
+		n.attempted = append(n.attempted, dockerContainer)
 		if err := dockerContainer.StartUsing(ctx, n.dockerNetwork); err != nil {
-			return fmt.Errorf("starting docker container: %s", err)
+			return n.containerFailed(ctx, dockerContainer, fmt.Errorf("starting docker container: %s", err))
 		}
+		n.containerStarted(dockerContainer, started)
 	}
 	if delay > 0 {
 		fmt.Printf("Sleeping for %s while containers start\n", delay)
 		time.Sleep(delay)
 	}
+	for _, dockerContainer := range n.dockerContainers {
+		if err := runHooks(ctx, hooksOf(dockerContainer).AfterReady); err != nil {
+			return n.containerFailed(ctx, dockerContainer, fmt.Errorf("running container after ready hooks: %s", err))
+		}
+	}
+	if err := runHooks(ctx, n.hooks.AfterReady); err != nil {
+		return n.failed(ctx, fmt.Errorf("running after ready hooks: %s", err))
+	}
+	for i := range n.timings {
+		n.timings[i].ReadyDuration = time.Since(n.timings[i].startedAt)
+		n.emit(Event{Type: EventContainerReady, Container: n.timings[i].Container, Image: n.timings[i].Image, Duration: n.timings[i].ReadyDuration})
+	}
 	return nil
 }
 
+// containerStarted reports that dockerContainer, which may have been started by another container rather than by the
+// network, such as an instance of a Lambda, has started, and records how long it took for the StartupReport
+func (n *NetworkOfDockerContainers) containerStarted(dockerContainer StartableDockerContainer, started time.Time) {
+	timing := ContainerTiming{Container: containerNameOf(dockerContainer), Image: imageOf(dockerContainer), StartDuration: time.Since(started), startedAt: time.Now()}
+	n.timings = append(n.timings, timing)
+	n.emit(Event{Type: EventContainerStarted, Container: timing.Container, Image: timing.Image, Duration: timing.StartDuration})
+}
+
+// containerFailed reports the failure of dockerContainer and runs its failure hooks and then those of the network
+func (n *NetworkOfDockerContainers) containerFailed(ctx context.Context, dockerContainer StartableDockerContainer, err error) error {
+	n.emit(Event{Type: EventContainerFailed, Container: containerNameOf(dockerContainer), Image: imageOf(dockerContainer), Err: err})
+	return n.failed(ctx, err, hooksOf(dockerContainer))
+}
+
+// failed runs the failure hooks of the failing container, if any, and then those of the network, before rolling back
+// the start of the network
+func (n *NetworkOfDockerContainers) failed(ctx context.Context, err error, containerHooks ...LifecycleHooks) error {
+	for _, hooks := range containerHooks {
+		runFailureHooks(ctx, hooks.OnFailure, err)
+	}
+	runFailureHooks(ctx, n.hooks.OnFailure, err)
+	if rollbackErr := n.rollback(ctx); rollbackErr != nil {
+		return fmt.Errorf("%s, and then rolling back: %s", err, rollbackErr)
+	}
+	return err
+}
+
+// rollback stops, in reverse order, the containers that were started, or attempted to be, and removes the docker network
+func (n *NetworkOfDockerContainers) rollback(ctx context.Context) error {
+	if n.dockerNetwork == nil {
+		return nil
+	}
+	var errs []error
+	for i := len(n.attempted) - 1; i >= 0; i-- {
+		if err := n.attempted[i].Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping docker container: %v", err))
+		}
+	}
+	n.attempted = nil
+	if err := n.removeSubNetworks(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := n.containerProvider().RemoveNetwork(ctx, n.dockerNetwork); err != nil {
+		errs = append(errs, fmt.Errorf("removing network: %v", err))
+	}
+	n.dockerNetwork = nil
+	return errors.Join(errs...)
+}
+
+// Stop stops every container and removes the networks, carrying on past failures so that nothing is left running, then
+// closes the provider and returns all the errors that it met.  A network that never started, or that was rolled back
+// when it failed to, has nothing to stop, so deferring Stop straight after StartWithDelay is safe
 func (n *NetworkOfDockerContainers) Stop() error {
 	ctx := context.Background()
+	if n.dockerNetwork == nil {
+		return n.closeProvider()
+	}
+	var errs []error
+	if err := runHooks(ctx, n.hooks.BeforeStop); err != nil {
+		errs = append(errs, fmt.Errorf("running before stop hooks: %v", err))
+	}
 	for _, dockerContainer := range n.dockerContainers {
+		if err := runHooks(ctx, hooksOf(dockerContainer).BeforeStop); err != nil {
+			errs = append(errs, fmt.Errorf("running container before stop hooks: %v", err))
+		}
+		stopping := time.Now()
 		if err := dockerContainer.Stop(ctx); err != nil {
-			return fmt.Errorf("stopping docker container: %v", err)
+			errs = append(errs, fmt.Errorf("stopping docker container: %v", err))
+			continue
 		}
+		n.emit(Event{Type: EventContainerStopped, Container: containerNameOf(dockerContainer), Image: imageOf(dockerContainer), Duration: time.Since(stopping)})
+	}
+	if err := n.removeSubNetworks(ctx); err != nil {
+		errs = append(errs, err)
 	}
-	if err := n.dockerNetwork.Remove(ctx); err != nil {
-		return fmt.Errorf("removing network: %v", err)
+	if err := n.containerProvider().RemoveNetwork(ctx, n.dockerNetwork); err != nil {
+		errs = append(errs, fmt.Errorf("removing network: %v", err))
+	}
+	n.dockerNetwork = nil
+	if err := n.closeProvider(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// closeProvider closes the provider if it is an io.Closer, as the default one is
+func (n *NetworkOfDockerContainers) closeProvider() error {
+	if closer, ok := n.containerProvider().(io.Closer); ok {
+		return closer.Close()
 	}
 	return nil
 }
@@ -1,11 +1,10 @@
 package testcontainernetwork
 
 import (
-	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -48,10 +47,10 @@ func TestDockerContainerNetwork(t *testing.T) {
 	suite := godog.TestSuite{
 		TestSuiteInitializer: func(ctx *godog.TestSuiteContext) {
 			ctx.BeforeSuite(steps.startContainerNetwork)
-			ctx.BeforeSuite(steps.initialiseDynamoDb)
 			ctx.AfterSuite(steps.stopContainerNetwork)
 		},
 		ScenarioInitializer: func(ctx *godog.ScenarioContext) {
+			ctx.Before(steps.restoreContainerNetwork)
 			ctx.Step(`^the Lambda is triggered$`, steps.theLambdaIsTriggered)
 			ctx.Step(`^the database credentials are read from the Secrets Manager$`, steps.theDatabaseCredentialsAreReadFromSSM)
 			ctx.Step(`^the external API endpoint is hit`, steps.theExternalApiEndpointIsHit)
@@ -127,6 +126,7 @@ func (s *steps) startContainerNetwork() {
 			Port:     dynamoDbPort,
 		},
 	}
+	s.dynamoDbContainer.AfterReady(s.createDynamoDbTable)
 
 	s.auroraContainer = PostgresDockerContainer{
 		Config: PostgresDockerContainerConfig{
@@ -150,8 +150,8 @@ func (s *steps) startContainerNetwork() {
 
 	s.lambdaContainer = LambdaDockerContainer{
 		Config: LambdaDockerContainerConfig{
-			Hostname:   "lambda",
-			Executable: "test-assets/lambda/main",
+			Hostname: "lambda",
+			Package:  "test-assets/lambda",
 			Environment: map[string]string{
 				"API_ENDPOINT":        fmt.Sprintf("http://%s:%d", externalApiHostname, externalApiPort),
 				"SQS_ENDPOINT":        fmt.Sprintf("http://%s:%d", sqsHostname, sqsPort),
@@ -176,19 +176,31 @@ func (s *steps) startContainerNetwork() {
 			WithDockerContainer(&s.dynamoDbContainer).
 			WithDockerContainer(&s.auroraContainer).
 			WithDockerContainer(&s.flywayContainer)
-	_ = s.networkOfDockerContainers.StartWithDelay(5 * time.Second)
+	if err := s.networkOfDockerContainers.StartWithDelay(5 * time.Second); err != nil {
+		log.Fatalf("starting docker containers: %v", err)
+	}
+	if err := s.networkOfDockerContainers.Snapshot(); err != nil {
+		log.Fatalf("snapshotting docker containers: %v", err)
+	}
+}
+
+func (s *steps) restoreContainerNetwork(ctx context.Context, _ *godog.Scenario) (context.Context, error) {
+	return ctx, s.networkOfDockerContainers.Restore()
 }
 
 func (s *steps) stopContainerNetwork() {
+	if testing.Verbose() {
+		s.t.Log("\n" + s.networkOfDockerContainers.StartupReport().String())
+	}
 	if err := s.networkOfDockerContainers.Stop(); err != nil {
 		log.Fatalf("stopping docker containers: %v", err)
 	}
 }
 
-func (s *steps) initialiseDynamoDb() {
+func (s *steps) createDynamoDbTable(context.Context) error {
 	dynamoDbClient, err := clients.DynamoDbClient{}.New("localhost", s.dynamoDbContainer.MappedPort())
 	if err != nil {
-		log.Fatalf("creating DynamoDB client: %v", err)
+		return fmt.Errorf("creating DynamoDB client: %v", err)
 	}
 
 	i := &dynamodb.CreateTableInput{
@@ -211,28 +223,15 @@ func (s *steps) initialiseDynamoDb() {
 		TableName: aws.String(dynamoDbTableName),
 	}
 	if err = dynamoDbClient.CreateTable(i); err != nil {
-		log.Fatalf("creating table: %v", err)
+		return fmt.Errorf("creating table: %v", err)
 	}
+	return nil
 }
 
 func (s *steps) theLambdaIsTriggered() {
-	request := events.APIGatewayProxyRequest{Path: fmt.Sprintf("/api-gateway-stage")}
-	requestJsonBytes, err := json.Marshal(request)
-	if err != nil {
-		log.Fatalf("marshalling lambda request %v", err)
-	}
-	response, err := http.Post(s.lambdaContainer.InvocationUrl(), "application/json", bytes.NewReader(requestJsonBytes))
-	if err != nil {
-		log.Fatalf("triggering lambda: %v", err)
-	}
-
-	if response.StatusCode != 200 {
-		buf := new(bytes.Buffer)
-		if _, err := buf.ReadFrom(response.Body); err != nil {
-			panic(err)
-		}
-		body := buf.String()
-		log.Fatalf("invoking Lambda: %d %s", response.StatusCode, body)
+	request := NewApiGatewayV1Request(http.MethodGet, "/api-gateway-stage").Build()
+	if _, err := s.lambdaContainer.Invoke(context.Background(), request); err != nil {
+		log.Fatalf("invoking Lambda: %v", err)
 	}
 }
 
@@ -273,10 +272,13 @@ func (s *steps) theDatabaseCredentialsAreReadFromSSM() {
 }
 
 func (s *steps) theLambdaWritesTheMessageToTheLog() {
-	buffer, _ := s.lambdaContainer.Log()
-	matched, err := regexp.Match("Wiremock returned a message of Hello World!", buffer.Bytes())
+	invocation, err := s.lambdaContainer.LastInvocation()
+	if err != nil {
+		s.t.Fatalf("finding the Lambda invocation in its log: %v", err)
+	}
+	matched, err := regexp.MatchString("Wiremock returned a message of Hello World!", invocation.Output())
 	if matched != true || err != nil {
-		s.t.Errorf("Lambda log did not contain expected value. Expected: \"Wiremock returned a message of Hello World!\", Got: %s", buffer.String())
+		s.t.Errorf("Lambda log did not contain expected value. Expected: \"Wiremock returned a message of Hello World!\", Got: %s", invocation.Output())
 	}
 }
 
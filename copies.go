@@ -0,0 +1,68 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"fmt"
+	"github.com/testcontainers/testcontainers-go"
+	"io/fs"
+	"path"
+)
+
+// directoryCopy is a directory tree to be copied into a container before it starts
+type directoryCopy struct {
+	fsys   fs.FS
+	target string
+}
+
+// CopyDirectory copies the directory tree in fsys into the container at target every time it is created, before it
+// starts, which works where bind mounts don't, such as when the docker daemon can't see the host's filesystem.  The
+// tree can come from the host, with os.DirFS, or be compiled into the test binary with embed.FS, in which case use
+// fs.Sub to copy a directory within it.  Files that are executable in fsys are copied with mode 0755, and others 0644
+func (c *DockerContainer) CopyDirectory(fsys fs.FS, target string) {
+	c.copies = append(c.copies, directoryCopy{fsys: fsys, target: target})
+}
+
+// copyDirectoryOnStart copies the directory tree into the container the next time it is created, for containers that
+// work out what to copy as they start
+func (c *DockerContainer) copyDirectoryOnStart(fsys fs.FS, target string) {
+	c.pendingCopies = append(c.pendingCopies, directoryCopy{fsys: fsys, target: target})
+}
+
+// mountDirectory makes a directory available read-only at target in the container described by req, copying it from
+// fsys if it is set, and otherwise mounting the host directory source according to the mount strategy
+func (c *DockerContainer) mountDirectory(req *testcontainers.ContainerRequest, fsys fs.FS, source string, target string) {
+	if fsys != nil {
+		c.copyDirectoryOnStart(fsys, target)
+		return
+	}
+	c.mountHostDirectory(req, source, target)
+}
+
+// copyDirectories copies the directory trees into the container, file by file, creating the directories as it goes
+func (c *DockerContainer) copyDirectories(ctx context.Context) error {
+	copies := append(append([]directoryCopy{}, c.copies...), c.pendingCopies...)
+	c.pendingCopies = nil
+	for _, directory := range copies {
+		if err := fs.WalkDir(directory.fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			content, err := fs.ReadFile(directory.fsys, filePath)
+			if err != nil {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			var fileMode int64 = configFileMode
+			if info.Mode()&0o111 != 0 {
+				fileMode = executableFileMode
+			}
+			return c.testContainer.CopyToContainer(ctx, content, path.Join(directory.target, filePath), fileMode)
+		}); err != nil {
+			return fmt.Errorf("copying directory to %s in docker container: %w", directory.target, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package testcontainernetwork
+
+import (
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFlywayDockerContainer_CopiesFilesFromFS(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	flywayContainer := &FlywayDockerContainer{Config: FlywayDockerContainerConfig{
+		Hostname: "flyway",
+		SqlFiles: fstest.MapFS{
+			"V1__create.sql":         {Data: []byte("create table t (id int);")},
+			"seed/V2__seed.sql":      {Data: []byte("insert into t values (1);")},
+			"callbacks/afterMigrate": {Data: []byte("#!/bin/sh"), Mode: 0o755},
+		},
+		ConfigFiles: fstest.MapFS{"flyway.conf": {Data: []byte("flyway.url=x")}},
+	}}
+	network := fakeNetwork(provider, flywayContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("flyway")
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Empty(t, hostConfig.Mounts)
+	assert.Equal(t, []string{
+		"copy to container flyway 24 bytes -> /flyway/sql/V1__create.sql (644)",
+		"copy to container flyway 9 bytes -> /flyway/sql/callbacks/afterMigrate (755)",
+		"copy to container flyway 25 bytes -> /flyway/sql/seed/V2__seed.sql (644)",
+		"copy to container flyway 12 bytes -> /flyway/conf/flyway.conf (644)",
+	}, callsAsStrings(provider.Calls())[3:7])
+}
+
+func TestWiremockDockerContainer_CopiesMappingsFromFS(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	wiremockContainer := &WiremockDockerContainer{Config: WiremockDockerContainerConfig{
+		Hostname:    "wiremock",
+		Port:        8080,
+		ConfigFiles: fstest.MapFS{"mappings.json": {Data: []byte("{}")}},
+	}}
+	network := fakeNetwork(provider, wiremockContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container wiremock 2 bytes -> /home/wiremock/mappings/mappings.json (644)")
+}
+
+func TestDockerContainer_CopyDirectoryCopiesEveryTimeContainerIsCreated(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	postgresContainer := &PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "aurora", Port: 5432}}
+	postgresContainer.CopyDirectory(fstest.MapFS{"init.sql": {Data: []byte("select 1;")}}, "/docker-entrypoint-initdb.d")
+	network := fakeNetwork(provider, postgresContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+	assert.NoError(t, network.StartWithDelay(0))
+
+	var copies []string
+	for _, call := range provider.Calls() {
+		if call.Operation == FakeCopyToContainer {
+			copies = append(copies, call.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"copy to container aurora 9 bytes -> /docker-entrypoint-initdb.d/init.sql (644)",
+		"copy to container aurora 9 bytes -> /docker-entrypoint-initdb.d/init.sql (644)",
+	}, copies)
+}
@@ -2,11 +2,18 @@ package testcontainernetwork
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/mikebharris/testcontainernetwork-go/clients"
 	"github.com/testcontainers/testcontainers-go"
 )
 
+const dynamoDbImage = "amazon/dynamodb-local"
+
 type DynamoDbDockerContainerConfig struct {
 	Hostname string
 	Port     int
@@ -14,13 +21,26 @@ type DynamoDbDockerContainerConfig struct {
 
 type DynamoDbDockerContainer struct {
 	DockerContainer
-	Config DynamoDbDockerContainerConfig
+	Config        DynamoDbDockerContainerConfig
+	snapshot      []dynamoDbTableSnapshot
+	streamReaders []dynamoDbStreamReader
+}
+
+// dynamoDbStreamReader reads the streams of the tables, and is paused while they are restored so that it doesn't take
+// the items that the restore puts back for new changes
+type dynamoDbStreamReader interface {
+	pauseForRestore()
+	resumeAfterRestore(ctx context.Context) error
+}
+
+func (c *DynamoDbDockerContainer) Image() string {
+	return dynamoDbImage
 }
 
 func (c *DynamoDbDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 	req := testcontainers.ContainerRequest{
-		Image:        "amazon/dynamodb-local",
+		Image:        c.Image(),
 		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
 		Name:         c.Config.Hostname,
 		Hostname:     c.Config.Hostname,
@@ -30,16 +50,117 @@ func (c *DynamoDbDockerContainer) StartUsing(ctx context.Context, dockerNetwork
 		},
 		Entrypoint: []string{"java", "-jar", "DynamoDBLocal.jar", "-inMemory", "-sharedDb"},
 	}
-	var err error
-	c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	})
+	return c.createAndStart(ctx, req)
+}
+
+type dynamoDbTableSnapshot struct {
+	createTableInput *dynamodb.CreateTableInput
+	items            []map[string]types.AttributeValue
+}
+
+// Snapshot records the definition and items of each table
+func (c *DynamoDbDockerContainer) Snapshot(context.Context) error {
+	dynamoDbClient, err := clients.DynamoDbClient{}.New("localhost", c.MappedPort())
+	if err != nil {
+		return fmt.Errorf("creating DynamoDB client: %w", err)
+	}
+	tableNames, err := dynamoDbClient.GetTableNames()
 	if err != nil {
-		return fmt.Errorf("creating container: %w", err)
+		return err
 	}
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+	c.snapshot = nil
+	for _, tableName := range tableNames {
+		description, err := dynamoDbClient.DescribeTable(tableName)
+		if err != nil {
+			return err
+		}
+		items, err := dynamoDbClient.GetItemsInTable(tableName)
+		if err != nil {
+			return err
+		}
+		c.snapshot = append(c.snapshot, dynamoDbTableSnapshot{createTableInput: createTableInputFrom(description), items: items})
 	}
 	return nil
 }
+
+// Restore drops all the tables and recreates those in the snapshot along with their items, pausing any event source
+// mappings that read their streams until it has done so
+func (c *DynamoDbDockerContainer) Restore(ctx context.Context) error {
+	for _, reader := range c.streamReaders {
+		reader.pauseForRestore()
+	}
+	err := c.restoreTables()
+	for _, reader := range c.streamReaders {
+		err = errors.Join(err, reader.resumeAfterRestore(ctx))
+	}
+	return err
+}
+
+func (c *DynamoDbDockerContainer) restoreTables() error {
+	dynamoDbClient, err := clients.DynamoDbClient{}.New("localhost", c.MappedPort())
+	if err != nil {
+		return fmt.Errorf("creating DynamoDB client: %w", err)
+	}
+	tableNames, err := dynamoDbClient.GetTableNames()
+	if err != nil {
+		return err
+	}
+	for _, tableName := range tableNames {
+		if err := dynamoDbClient.DeleteTable(tableName); err != nil {
+			return fmt.Errorf("deleting table %s: %w", tableName, err)
+		}
+	}
+	for _, table := range c.snapshot {
+		if err := dynamoDbClient.CreateTable(table.createTableInput); err != nil {
+			return fmt.Errorf("creating table %s: %w", *table.createTableInput.TableName, err)
+		}
+		for _, item := range table.items {
+			if err := dynamoDbClient.PutItem(&dynamodb.PutItemInput{TableName: table.createTableInput.TableName, Item: item}); err != nil {
+				return fmt.Errorf("putting item in table %s: %w", *table.createTableInput.TableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// createTableInputFrom converts the description of a table into the input that would create it afresh
+func createTableInputFrom(description *types.TableDescription) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:            description.TableName,
+		AttributeDefinitions: description.AttributeDefinitions,
+		KeySchema:            description.KeySchema,
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	if description.StreamSpecification != nil && aws.ToBool(description.StreamSpecification.StreamEnabled) {
+		input.StreamSpecification = description.StreamSpecification
+	}
+	if description.ProvisionedThroughput != nil && aws.ToInt64(description.ProvisionedThroughput.ReadCapacityUnits) > 0 {
+		input.BillingMode = types.BillingModeProvisioned
+		input.ProvisionedThroughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  description.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: description.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	for _, index := range description.GlobalSecondaryIndexes {
+		globalSecondaryIndex := types.GlobalSecondaryIndex{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		}
+		if input.BillingMode == types.BillingModeProvisioned && index.ProvisionedThroughput != nil {
+			globalSecondaryIndex.ProvisionedThroughput = &types.ProvisionedThroughput{
+				ReadCapacityUnits:  index.ProvisionedThroughput.ReadCapacityUnits,
+				WriteCapacityUnits: index.ProvisionedThroughput.WriteCapacityUnits,
+			}
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, globalSecondaryIndex)
+	}
+	for _, index := range description.LocalSecondaryIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		})
+	}
+	return input
+}
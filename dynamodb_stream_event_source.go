@@ -0,0 +1,373 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/mikebharris/testcontainernetwork-go/clients"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DynamoDbStreamStartingPosition is where in the stream a mapping starts reading records
+type DynamoDbStreamStartingPosition string
+
+const (
+	// DynamoDbStreamStartingPositionLatest reads only the records written after the mapping starts
+	DynamoDbStreamStartingPositionLatest DynamoDbStreamStartingPosition = "LATEST"
+	// DynamoDbStreamStartingPositionTrimHorizon reads all the records in the stream
+	DynamoDbStreamStartingPositionTrimHorizon DynamoDbStreamStartingPosition = "TRIM_HORIZON"
+)
+
+type DynamoDbStreamEventSourceMappingConfig struct {
+	Table string
+	// StartingPosition defaults to DynamoDbStreamStartingPositionLatest
+	StartingPosition DynamoDbStreamStartingPosition
+	// BatchSize is the most records that the Lambda is invoked with at once, which defaults to 100 and can be up to 1000
+	BatchSize int
+	// MaximumRetryAttempts is how many times a batch that the Lambda fails to process is retried before it is skipped,
+	// with -1 retrying it until the Lambda succeeds.  Unlike in AWS, where -1 is the default, the default of 0 skips the
+	// batch straight away, so that one failure doesn't hold up the records that follow it for the rest of a test
+	MaximumRetryAttempts int
+}
+
+// DynamoDbStreamEventSourceMapping triggers a Lambda with the changes to the items in a table in a
+// DynamoDbDockerContainer, as an event source mapping does in AWS.  Once the network is ready, it reads the records
+// from each shard of the table's stream in order and invokes the Lambda with batches of them as an
+// events.DynamoDBEvent.  The table must have a stream; if it doesn't exist yet, the mapping waits for it to be created,
+// and then reads all its records whatever the StartingPosition, as it does the new stream that the table has when it is
+// created again.  When the table is created again by restoring a snapshot of the network, the mapping reads only the
+// records that follow the restore, so that the items it puts back don't trigger the Lambda in the next scenario
+type DynamoDbStreamEventSourceMapping struct {
+	Config   DynamoDbStreamEventSourceMappingConfig
+	DynamoDb *DynamoDbDockerContainer
+	Lambda   *LambdaDockerContainer
+
+	stream    dynamoDbStream
+	streamArn string
+	shards    map[string]*dynamoDbStreamShard
+	shardIds  []string
+	polled    bool
+	region    string
+	invoke    func(ctx context.Context, event any) (LambdaResponse, error)
+	poller    eventSourcePoller
+	mu        sync.Mutex
+}
+
+// dynamoDbStream is the part of clients.DynamoDbStreamsClient that the mapping uses
+type dynamoDbStream interface {
+	StreamArn(ctx context.Context, table string) (string, error)
+	Shards(ctx context.Context, streamArn string) ([]types.Shard, error)
+	ShardIterator(ctx context.Context, streamArn string, shardId string, iteratorType types.ShardIteratorType) (string, error)
+	ShardIteratorAfter(ctx context.Context, streamArn string, shardId string, sequenceNumber string) (string, error)
+	GetRecords(ctx context.Context, shardIterator string, limit int32) ([]types.Record, string, error)
+}
+
+// dynamoDbStreamShard is how far the mapping has read a shard, along with the batch that it is retrying, if any, and
+// where it started and the sequence number of the last record read, from which an iterator that expires is renewed
+type dynamoDbStreamShard struct {
+	id             string
+	start          types.ShardIteratorType
+	iterator       string
+	sequenceNumber string
+	pending        []types.Record
+	retries        int
+}
+
+// WithDynamoDbStreamEventSourceMapping starts the mapping reading its stream once the network is ready, and stops it
+// before the containers are stopped, or are rolled back because the network failed to start after the mapping did
+func (n NetworkOfDockerContainers) WithDynamoDbStreamEventSourceMapping(mapping *DynamoDbStreamEventSourceMapping) NetworkOfDockerContainers {
+	return n.WithHooks(LifecycleHooks{
+		AfterReady: []LifecycleHook{mapping.Start},
+		BeforeStop: []LifecycleHook{mapping.Stop},
+		OnFailure: []FailureHook{func(ctx context.Context, _ error) {
+			_ = mapping.Stop(ctx)
+		}},
+	})
+}
+
+// Start reads the stream in the background until Stop is called
+func (m *DynamoDbStreamEventSourceMapping) Start(context.Context) error {
+	streamsClient, err := clients.DynamoDbStreamsClient{}.New("localhost", m.DynamoDb.MappedPort())
+	if err != nil {
+		return fmt.Errorf("creating DynamoDB Streams client: %w", err)
+	}
+	m.stream = streamsClient
+	m.region = m.Lambda.Config.Region
+	m.invoke = m.Lambda.Invoke
+	m.DynamoDb.streamReaders = append(m.DynamoDb.streamReaders, m)
+	m.poller.start(m.Poll)
+	return nil
+}
+
+// Stop stops reading the stream, waiting for any invocation in progress to finish
+func (m *DynamoDbStreamEventSourceMapping) Stop(context.Context) error {
+	m.poller.stop()
+	if m.DynamoDb != nil {
+		m.DynamoDb.streamReaders = slices.DeleteFunc(m.DynamoDb.streamReaders, func(reader dynamoDbStreamReader) bool {
+			return reader == dynamoDbStreamReader(m)
+		})
+	}
+	return nil
+}
+
+// pauseForRestore waits for any poll in progress to finish and holds off the next until resumeAfterRestore
+func (m *DynamoDbStreamEventSourceMapping) pauseForRestore() {
+	m.mu.Lock()
+}
+
+// resumeAfterRestore starts reading the table's new stream from its end, past the records of the items that the
+// restore put back, and lets polling carry on
+func (m *DynamoDbStreamEventSourceMapping) resumeAfterRestore(ctx context.Context) error {
+	defer m.mu.Unlock()
+	m.forgetStream()
+	m.polled = true
+	if err := m.followStream(ctx); err != nil || m.streamArn == "" {
+		return err
+	}
+	if err := m.findShards(ctx, types.ShardIteratorTypeLatest); err != nil {
+		return m.forgetStreamIfDeleted(err)
+	}
+	return nil
+}
+
+// Errors returns the errors that reading the stream has met, such as the Lambda returning a function error
+func (m *DynamoDbStreamEventSourceMapping) Errors() []error {
+	return m.poller.errors()
+}
+
+// Poll reads a batch of records from each shard of the stream and invokes the Lambda with those it finds, or retries
+// the batch that last failed, returning the number of records that the Lambda was invoked with
+func (m *DynamoDbStreamEventSourceMapping) Poll(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.followStream(ctx); err != nil {
+		return 0, err
+	}
+	if m.streamArn == "" {
+		m.polled = true
+		return 0, nil
+	}
+	iteratorType := types.ShardIteratorTypeTrimHorizon
+	if !m.polled && m.Config.StartingPosition != DynamoDbStreamStartingPositionTrimHorizon {
+		iteratorType = types.ShardIteratorTypeLatest
+	}
+	if err := m.findShards(ctx, iteratorType); err != nil {
+		return 0, m.forgetStreamIfDeleted(err)
+	}
+	m.polled = true
+
+	var count int
+	var errs []error
+	for _, shardId := range m.shardIds {
+		n, err := m.pollShard(ctx, m.shards[shardId])
+		count += n
+		if err = m.forgetStreamIfDeleted(err); err != nil {
+			errs = append(errs, err)
+		}
+		if m.streamArn == "" {
+			break
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
+// followStream looks up the table's stream, which changes when the table is deleted and created again, and starts
+// reading the new stream's shards afresh
+func (m *DynamoDbStreamEventSourceMapping) followStream(ctx context.Context) error {
+	streamArn, err := m.stream.StreamArn(ctx, m.Config.Table)
+	if err != nil && !isDynamoDbStreamDeleted(err) {
+		return err
+	}
+	if streamArn != m.streamArn {
+		m.streamArn = streamArn
+		m.shards = nil
+		m.shardIds = nil
+	}
+	return nil
+}
+
+// forgetStreamIfDeleted drops the stream if err shows that it has been deleted, along with its table, so that the next
+// poll looks up the stream again, and otherwise returns err
+func (m *DynamoDbStreamEventSourceMapping) forgetStreamIfDeleted(err error) error {
+	if !isDynamoDbStreamDeleted(err) {
+		return err
+	}
+	m.forgetStream()
+	return nil
+}
+
+func (m *DynamoDbStreamEventSourceMapping) forgetStream() {
+	m.streamArn = ""
+	m.shards = nil
+	m.shardIds = nil
+}
+
+func isDynamoDbStreamDeleted(err error) bool {
+	var resourceNotFound *types.ResourceNotFoundException
+	return errors.As(err, &resourceNotFound)
+}
+
+// findShards starts reading any shards that it hasn't seen before at iteratorType, which Poll makes the starting
+// position if the stream existed when the mapping was started and otherwise, since all of their records are new, the
+// start of the shard
+func (m *DynamoDbStreamEventSourceMapping) findShards(ctx context.Context, iteratorType types.ShardIteratorType) error {
+	shards, err := m.stream.Shards(ctx, m.streamArn)
+	if err != nil {
+		return err
+	}
+	if m.shards == nil {
+		m.shards = map[string]*dynamoDbStreamShard{}
+	}
+	for _, shard := range shards {
+		shardId := aws.ToString(shard.ShardId)
+		if _, ok := m.shards[shardId]; ok {
+			continue
+		}
+		iterator, err := m.stream.ShardIterator(ctx, m.streamArn, shardId, iteratorType)
+		if err != nil {
+			return err
+		}
+		m.shards[shardId] = &dynamoDbStreamShard{id: shardId, start: iteratorType, iterator: iterator}
+		m.shardIds = append(m.shardIds, shardId)
+	}
+	return nil
+}
+
+func (m *DynamoDbStreamEventSourceMapping) pollShard(ctx context.Context, shard *dynamoDbStreamShard) (int, error) {
+	if len(shard.pending) == 0 {
+		if shard.iterator == "" {
+			return 0, nil
+		}
+		batchSize := m.Config.BatchSize
+		if batchSize == 0 {
+			batchSize = 100
+		}
+		records, iterator, err := m.stream.GetRecords(ctx, shard.iterator, int32(min(batchSize, 1000)))
+		var expiredIterator *types.ExpiredIteratorException
+		if errors.As(err, &expiredIterator) {
+			return 0, m.renewIterator(ctx, shard)
+		}
+		if err != nil {
+			return 0, err
+		}
+		shard.iterator = iterator
+		shard.pending = records
+		shard.retries = 0
+		if len(records) == 0 {
+			return 0, nil
+		}
+		if change := records[len(records)-1].Dynamodb; change != nil {
+			shard.sequenceNumber = aws.ToString(change.SequenceNumber)
+		}
+	}
+
+	count := len(shard.pending)
+	if _, err := m.invoke(ctx, m.dynamoDbEvent(shard.pending)); err != nil {
+		err = fmt.Errorf("invoking Lambda with records from %s: %w", m.Config.Table, err)
+		if m.Config.MaximumRetryAttempts >= 0 && shard.retries >= m.Config.MaximumRetryAttempts {
+			shard.pending = nil
+			return count, fmt.Errorf("%w; skipping %d records after %d retries", err, count, shard.retries)
+		}
+		shard.retries++
+		return count, err
+	}
+	shard.pending = nil
+	return count, nil
+}
+
+// renewIterator replaces an iterator that has expired with one that reads the shard from after the last record read,
+// or from where the mapping started reading it if it hasn't read any
+func (m *DynamoDbStreamEventSourceMapping) renewIterator(ctx context.Context, shard *dynamoDbStreamShard) error {
+	var iterator string
+	var err error
+	if shard.sequenceNumber == "" {
+		iterator, err = m.stream.ShardIterator(ctx, m.streamArn, shard.id, shard.start)
+	} else {
+		iterator, err = m.stream.ShardIteratorAfter(ctx, m.streamArn, shard.id, shard.sequenceNumber)
+	}
+	if err != nil {
+		return err
+	}
+	shard.iterator = iterator
+	return nil
+}
+
+// dynamoDbEvent converts the records into the event that the Lambda is invoked with, placing the stream in the Lambda's
+// region rather than that of DynamoDB Local
+func (m *DynamoDbStreamEventSourceMapping) dynamoDbEvent(records []types.Record) events.DynamoDBEvent {
+	region := regionOrDefault(m.region)
+	streamArn := m.streamArn
+	if arn := strings.SplitN(streamArn, ":", 5); len(arn) == 5 {
+		arn[3] = region
+		streamArn = strings.Join(arn, ":")
+	}
+	var event events.DynamoDBEvent
+	for _, record := range records {
+		eventRecord := events.DynamoDBEventRecord{
+			AWSRegion:      region,
+			EventID:        aws.ToString(record.EventID),
+			EventName:      string(record.EventName),
+			EventSource:    aws.ToString(record.EventSource),
+			EventVersion:   aws.ToString(record.EventVersion),
+			EventSourceArn: streamArn,
+		}
+		if change := record.Dynamodb; change != nil {
+			eventRecord.Change = events.DynamoDBStreamRecord{
+				ApproximateCreationDateTime: events.SecondsEpochTime{Time: aws.ToTime(change.ApproximateCreationDateTime)},
+				Keys:                        dynamoDbAttributeValuesFrom(change.Keys),
+				NewImage:                    dynamoDbAttributeValuesFrom(change.NewImage),
+				OldImage:                    dynamoDbAttributeValuesFrom(change.OldImage),
+				SequenceNumber:              aws.ToString(change.SequenceNumber),
+				SizeBytes:                   aws.ToInt64(change.SizeBytes),
+				StreamViewType:              string(change.StreamViewType),
+			}
+		}
+		event.Records = append(event.Records, eventRecord)
+	}
+	return event
+}
+
+func dynamoDbAttributeValuesFrom(values map[string]types.AttributeValue) map[string]events.DynamoDBAttributeValue {
+	if values == nil {
+		return nil
+	}
+	attributeValues := make(map[string]events.DynamoDBAttributeValue, len(values))
+	for name, value := range values {
+		attributeValues[name] = dynamoDbAttributeValueFrom(value)
+	}
+	return attributeValues
+}
+
+func dynamoDbAttributeValueFrom(value types.AttributeValue) events.DynamoDBAttributeValue {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberB:
+		return events.NewBinaryAttribute(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return events.NewBooleanAttribute(v.Value)
+	case *types.AttributeValueMemberBS:
+		return events.NewBinarySetAttribute(v.Value)
+	case *types.AttributeValueMemberL:
+		var list []events.DynamoDBAttributeValue
+		for _, item := range v.Value {
+			list = append(list, dynamoDbAttributeValueFrom(item))
+		}
+		return events.NewListAttribute(list)
+	case *types.AttributeValueMemberM:
+		return events.NewMapAttribute(dynamoDbAttributeValuesFrom(v.Value))
+	case *types.AttributeValueMemberN:
+		return events.NewNumberAttribute(v.Value)
+	case *types.AttributeValueMemberNS:
+		return events.NewNumberSetAttribute(v.Value)
+	case *types.AttributeValueMemberS:
+		return events.NewStringAttribute(v.Value)
+	case *types.AttributeValueMemberSS:
+		return events.NewStringSetAttribute(v.Value)
+	default:
+		return events.NewNullAttribute()
+	}
+}
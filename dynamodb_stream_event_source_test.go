@@ -0,0 +1,321 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeDynamoDbStream is a stream with a single shard, whose iterators are the index of the next record to read
+type fakeDynamoDbStream struct {
+	streamArn       string
+	records         []types.Record
+	iteratorTypes   []types.ShardIteratorType
+	expireIterators bool
+}
+
+func (s *fakeDynamoDbStream) StreamArn(context.Context, string) (string, error) {
+	return s.streamArn, nil
+}
+
+func (s *fakeDynamoDbStream) Shards(context.Context, string) ([]types.Shard, error) {
+	return []types.Shard{{ShardId: aws.String("shard-1")}}, nil
+}
+
+func (s *fakeDynamoDbStream) ShardIterator(_ context.Context, _ string, _ string, iteratorType types.ShardIteratorType) (string, error) {
+	s.iteratorTypes = append(s.iteratorTypes, iteratorType)
+	if iteratorType == types.ShardIteratorTypeLatest {
+		return strconv.Itoa(len(s.records)), nil
+	}
+	return "0", nil
+}
+
+func (s *fakeDynamoDbStream) ShardIteratorAfter(_ context.Context, _ string, _ string, sequenceNumber string) (string, error) {
+	s.iteratorTypes = append(s.iteratorTypes, types.ShardIteratorTypeAfterSequenceNumber)
+	after, _ := strconv.Atoi(sequenceNumber)
+	return strconv.Itoa(after), nil
+}
+
+func (s *fakeDynamoDbStream) GetRecords(_ context.Context, shardIterator string, limit int32) ([]types.Record, string, error) {
+	if s.expireIterators {
+		s.expireIterators = false
+		return nil, "", fmt.Errorf("getting records: %w", &types.ExpiredIteratorException{})
+	}
+	from, _ := strconv.Atoi(shardIterator)
+	to := min(from+int(limit), len(s.records))
+	return s.records[from:to], strconv.Itoa(to), nil
+}
+
+func (s *fakeDynamoDbStream) write(count int) {
+	for i := 0; i < count; i++ {
+		sequenceNumber := fmt.Sprintf("%021d", len(s.records)+1)
+		s.records = append(s.records, types.Record{
+			AwsRegion:    aws.String("eu-west-1"),
+			EventID:      aws.String(sequenceNumber),
+			EventName:    types.OperationTypeInsert,
+			EventSource:  aws.String("aws:dynamodb"),
+			EventVersion: aws.String("1.1"),
+			Dynamodb: &types.StreamRecord{
+				ApproximateCreationDateTime: aws.Time(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)),
+				Keys:                        map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: sequenceNumber}},
+				NewImage: map[string]types.AttributeValue{
+					"id":    &types.AttributeValueMemberS{Value: sequenceNumber},
+					"total": &types.AttributeValueMemberN{Value: "42"},
+					"lines": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"paid": &types.AttributeValueMemberBOOL{Value: true}}}}},
+				},
+				SequenceNumber: aws.String(sequenceNumber),
+				SizeBytes:      aws.Int64(64),
+				StreamViewType: types.StreamViewTypeNewAndOldImages,
+			},
+		})
+	}
+}
+
+func TestDynamoDbStreamEventSourceMapping_InvokesLambdaWithBatchesOfRecords(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "arn:aws:dynamodb:ddblocal:000000000000:table/orders/stream/2024-06-01T12:00:00.000"}
+	var invokedWith []events.DynamoDBEvent
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders", StartingPosition: DynamoDbStreamStartingPositionTrimHorizon, BatchSize: 2},
+		stream: stream,
+		region: "eu-west-2",
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			invokedWith = append(invokedWith, event.(events.DynamoDBEvent))
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(3)
+
+	first, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	second, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	third, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{2, 1, 0}, []int{first, second, third})
+	assert.Len(t, invokedWith, 2)
+	record := invokedWith[1].Records[0]
+	assert.Equal(t, "INSERT", record.EventName)
+	assert.Equal(t, "eu-west-2", record.AWSRegion)
+	assert.Equal(t, "arn:aws:dynamodb:eu-west-2:000000000000:table/orders/stream/2024-06-01T12:00:00.000", record.EventSourceArn)
+	assert.Equal(t, "000000000000000000003", record.Change.SequenceNumber)
+	assert.Equal(t, "000000000000000000003", record.Change.Keys["id"].String())
+	assert.Equal(t, "42", record.Change.NewImage["total"].Number())
+	assert.True(t, record.Change.NewImage["lines"].List()[0].Map()["paid"].Boolean())
+	assert.Equal(t, "NEW_AND_OLD_IMAGES", record.Change.StreamViewType)
+	assert.Equal(t, 2024, record.Change.ApproximateCreationDateTime.Year())
+}
+
+func TestDynamoDbStreamEventSourceMapping_StartsFromLatestByDefault(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream"}
+	var recordCount int
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders"},
+		stream: stream,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			recordCount += len(event.(events.DynamoDBEvent).Records)
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(2)
+
+	_, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	stream.write(1)
+	_, err = mapping.Poll(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []types.ShardIteratorType{types.ShardIteratorTypeLatest}, stream.iteratorTypes)
+	assert.Equal(t, 1, recordCount)
+}
+
+func TestDynamoDbStreamEventSourceMapping_ReadsStreamCreatedAfterStartingFromTheStart(t *testing.T) {
+	stream := &fakeDynamoDbStream{}
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders"},
+		stream: stream,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+
+	count, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	stream.streamArn = "stream"
+	stream.write(2)
+	count, err = mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []types.ShardIteratorType{types.ShardIteratorTypeTrimHorizon}, stream.iteratorTypes)
+}
+
+func TestDynamoDbStreamEventSourceMapping_RetriesFailingBatchThenSkipsIt(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream"}
+	var invokedWith []string
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders", StartingPosition: DynamoDbStreamStartingPositionTrimHorizon, BatchSize: 1, MaximumRetryAttempts: 2},
+		stream: stream,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			sequenceNumber := event.(events.DynamoDBEvent).Records[0].Change.SequenceNumber
+			invokedWith = append(invokedWith, sequenceNumber)
+			if sequenceNumber == "000000000000000000001" {
+				return LambdaResponse{}, &LambdaFunctionError{ErrorMessage: "database is down"}
+			}
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(2)
+
+	var errs []error
+	for i := 0; i < 4; i++ {
+		_, err := mapping.Poll(context.Background())
+		errs = append(errs, err)
+	}
+
+	assert.Equal(t, []string{"000000000000000000001", "000000000000000000001", "000000000000000000001", "000000000000000000002"}, invokedWith)
+	assert.EqualError(t, errs[0], "invoking Lambda with records from orders: Lambda function error: database is down")
+	assert.EqualError(t, errs[2], "invoking Lambda with records from orders: Lambda function error: database is down; skipping 1 records after 2 retries")
+	assert.NoError(t, errs[3])
+}
+
+func TestDynamoDbStreamEventSourceMapping_RetriesUntilSuccessWhenRetryAttemptsAreUnlimited(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream"}
+	var attempts int
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders", StartingPosition: DynamoDbStreamStartingPositionTrimHorizon, MaximumRetryAttempts: -1},
+		stream: stream,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			attempts++
+			if attempts < 5 {
+				return LambdaResponse{}, &LambdaFunctionError{ErrorMessage: "database is down"}
+			}
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(1)
+
+	for i := 0; i < 5; i++ {
+		_, _ = mapping.Poll(context.Background())
+	}
+	count, err := mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, 5, attempts)
+}
+
+func TestDynamoDbStreamEventSourceMapping_ReadsNewStreamOfTableCreatedAgainFromTheStart(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream-1"}
+	var sourceArns []string
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders"},
+		stream: stream,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			for _, record := range event.(events.DynamoDBEvent).Records {
+				sourceArns = append(sourceArns, record.EventSourceArn)
+			}
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	_, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	stream.write(2)
+	_, err = mapping.Poll(context.Background())
+	assert.NoError(t, err)
+
+	stream.streamArn, stream.records = "stream-2", nil
+	stream.write(1)
+	count, err := mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"stream-1", "stream-1", "stream-2"}, sourceArns)
+	assert.Equal(t, []types.ShardIteratorType{types.ShardIteratorTypeLatest, types.ShardIteratorTypeTrimHorizon}, stream.iteratorTypes)
+}
+
+func TestDynamoDbStreamEventSourceMapping_DoesNotInvokeLambdaWithItemsPutBackByRestore(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream-1"}
+	var invokedWith []string
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders", StartingPosition: DynamoDbStreamStartingPositionTrimHorizon},
+		stream: stream,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			for _, record := range event.(events.DynamoDBEvent).Records {
+				invokedWith = append(invokedWith, record.Change.SequenceNumber)
+			}
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(2)
+	_, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+
+	mapping.pauseForRestore()
+	stream.streamArn, stream.records = "stream-2", nil
+	stream.write(2)
+	assert.NoError(t, mapping.resumeAfterRestore(context.Background()))
+	count, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	stream.write(1)
+	count, err = mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"000000000000000000001", "000000000000000000002", "000000000000000000003"}, invokedWith)
+}
+
+func TestDynamoDbStreamEventSourceMapping_RenewsExpiredIteratorAfterLastRecordRead(t *testing.T) {
+	stream := &fakeDynamoDbStream{streamArn: "stream"}
+	var invokedWith []string
+	mapping := &DynamoDbStreamEventSourceMapping{
+		Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders", StartingPosition: DynamoDbStreamStartingPositionTrimHorizon},
+		stream: stream,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			for _, record := range event.(events.DynamoDBEvent).Records {
+				invokedWith = append(invokedWith, record.EventID)
+			}
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+	stream.write(2)
+	_, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+
+	stream.write(1)
+	stream.expireIterators = true
+	count, err := mapping.Poll(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"000000000000000000001", "000000000000000000002", "000000000000000000003"}, invokedWith)
+	assert.Equal(t, []types.ShardIteratorType{types.ShardIteratorTypeTrimHorizon, types.ShardIteratorTypeAfterSequenceNumber}, stream.iteratorTypes)
+}
+
+func TestDynamoDbStreamEventSourceMapping_StopsWhenNetworkFailsToStartAfterIt(t *testing.T) {
+	dynamoDbContainer := &DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders"}}
+	mapping := &DynamoDbStreamEventSourceMapping{Config: DynamoDbStreamEventSourceMappingConfig{Table: "orders"}, DynamoDb: dynamoDbContainer, Lambda: lambdaContainer}
+	network := fakeNetwork(&FakeContainerProvider{}, dynamoDbContainer, lambdaContainer).
+		WithDynamoDbStreamEventSourceMapping(mapping).
+		WithHooks(LifecycleHooks{AfterReady: []LifecycleHook{func(context.Context) error {
+			return errors.New("address already in use")
+		}}})
+
+	assert.ErrorContains(t, network.StartWithDelay(0), "address already in use")
+
+	assert.Nil(t, mapping.poller.cancel)
+}
@@ -0,0 +1,65 @@
+package testcontainernetwork
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateTableInputFrom_ProvisionedTable(t *testing.T) {
+	attributeDefinitions := []types.AttributeDefinition{{AttributeName: aws.String("Message"), AttributeType: types.ScalarAttributeTypeS}}
+	keySchema := []types.KeySchemaElement{{AttributeName: aws.String("Message"), KeyType: types.KeyTypeHash}}
+	description := &types.TableDescription{
+		TableName:            aws.String("table"),
+		AttributeDefinitions: attributeDefinitions,
+		KeySchema:            keySchema,
+		ProvisionedThroughput: &types.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(2),
+		},
+		StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(false)},
+	}
+
+	assert.Equal(t, &dynamodb.CreateTableInput{
+		TableName:            aws.String("table"),
+		AttributeDefinitions: attributeDefinitions,
+		KeySchema:            keySchema,
+		BillingMode:          types.BillingModeProvisioned,
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(2),
+		},
+	}, createTableInputFrom(description))
+}
+
+func TestCreateTableInputFrom_PayPerRequestTableWithIndexAndStream(t *testing.T) {
+	keySchema := []types.KeySchemaElement{{AttributeName: aws.String("Id"), KeyType: types.KeyTypeHash}}
+	projection := &types.Projection{ProjectionType: types.ProjectionTypeAll}
+	streamSpecification := &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewTypeNewImage}
+	description := &types.TableDescription{
+		TableName:             aws.String("table"),
+		KeySchema:             keySchema,
+		ProvisionedThroughput: &types.ProvisionedThroughputDescription{ReadCapacityUnits: aws.Int64(0), WriteCapacityUnits: aws.Int64(0)},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{
+			IndexName:             aws.String("index"),
+			KeySchema:             keySchema,
+			Projection:            projection,
+			ProvisionedThroughput: &types.ProvisionedThroughputDescription{ReadCapacityUnits: aws.Int64(0), WriteCapacityUnits: aws.Int64(0)},
+		}},
+		StreamSpecification: streamSpecification,
+	}
+
+	assert.Equal(t, &dynamodb.CreateTableInput{
+		TableName:   aws.String("table"),
+		KeySchema:   keySchema,
+		BillingMode: types.BillingModePayPerRequest,
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName:  aws.String("index"),
+			KeySchema:  keySchema,
+			Projection: projection,
+		}},
+		StreamSpecification: streamSpecification,
+	}, createTableInputFrom(description))
+}
@@ -0,0 +1,68 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// eventSourcePoller runs the poll of an event source mapping in the background, recording the errors it meets
+type eventSourcePoller struct {
+	// pause is how long to wait after a poll that fails or finds nothing to do, which defaults to 100ms
+	pause  time.Duration
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+	errs   []error
+}
+
+// start calls poll repeatedly until stop is called, pausing briefly whenever it fails or finds nothing to do
+func (p *eventSourcePoller) start(poll func(ctx context.Context) (int, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		for ctx.Err() == nil {
+			count, err := poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				p.failed(err)
+			}
+			if err != nil || count == 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(p.pauseDuration()):
+				}
+			}
+		}
+	}()
+}
+
+func (p *eventSourcePoller) pauseDuration() time.Duration {
+	if p.pause == 0 {
+		return 100 * time.Millisecond
+	}
+	return p.pause
+}
+
+// stop stops polling, waiting for any poll in progress to finish
+func (p *eventSourcePoller) stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+	p.cancel = nil
+}
+
+func (p *eventSourcePoller) failed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errs = append(p.errs, err)
+}
+
+func (p *eventSourcePoller) errors() []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]error{}, p.errs...)
+}
@@ -0,0 +1,131 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventImagePulled      EventType = "image pulled"
+	EventImageLoaded      EventType = "image loaded"
+	EventContainerCreated EventType = "container created"
+	EventContainerStarted EventType = "container started"
+	EventContainerReady   EventType = "container ready"
+	EventContainerStopped EventType = "container stopped"
+	EventContainerFailed  EventType = "container failed"
+	EventLambdaReloaded   EventType = "lambda reloaded"
+	// EventLambdaCoverageMissing warns that a Lambda with a CoverageDir wrote no coverage counters before it stopped
+	EventLambdaCoverageMissing EventType = "lambda coverage missing"
+)
+
+// Event describes something that happened in the lifecycle of the network; Duration is how long it took, which for
+// EventContainerReady is the time from the container being started to the network being ready
+type Event struct {
+	Type      EventType
+	Container string
+	Image     string
+	Duration  time.Duration
+	Err       error
+}
+
+// EventListener is notified of the events in the lifecycle of the network, and may be called concurrently while
+// images are being pulled
+type EventListener interface {
+	OnEvent(event Event)
+}
+
+type EventListenerFunc func(event Event)
+
+func (f EventListenerFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// SlogEventListener writes the events to a structured logger, at error level for failures, warning level for missing
+// coverage and info level otherwise
+type SlogEventListener struct {
+	Logger *slog.Logger
+}
+
+func (l SlogEventListener) OnEvent(event Event) {
+	attrs := []slog.Attr{slog.Duration("duration", event.Duration)}
+	if event.Container != "" {
+		attrs = append(attrs, slog.String("container", event.Container))
+	}
+	if event.Image != "" {
+		attrs = append(attrs, slog.String("image", event.Image))
+	}
+	level := slog.LevelInfo
+	if event.Err != nil {
+		level = slog.LevelError
+		if event.Type == EventLambdaCoverageMissing {
+			level = slog.LevelWarn
+		}
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+	l.Logger.LogAttrs(context.Background(), level, string(event.Type), attrs...)
+}
+
+func (n NetworkOfDockerContainers) WithEventListener(listener EventListener) NetworkOfDockerContainers {
+	n.eventListeners = append(n.eventListeners, listener)
+	return n
+}
+
+func (n NetworkOfDockerContainers) WithLogger(logger *slog.Logger) NetworkOfDockerContainers {
+	return n.WithEventListener(SlogEventListener{Logger: logger})
+}
+
+func (n *NetworkOfDockerContainers) emit(event Event) {
+	for _, listener := range n.eventListeners {
+		listener.OnEvent(event)
+	}
+}
+
+func containerNameOf(dockerContainer StartableDockerContainer) string {
+	if networked, ok := dockerContainer.(networkedDockerContainer); ok && networked.containerName() != "" {
+		return networked.containerName()
+	}
+	return fmt.Sprintf("%T", dockerContainer)
+}
+
+func imageOf(dockerContainer StartableDockerContainer) string {
+	if pullable, ok := dockerContainer.(PullableDockerContainer); ok {
+		return pullable.Image()
+	}
+	return ""
+}
+
+// ContainerTiming records how long a container took to start, and how long after it started the network was ready
+type ContainerTiming struct {
+	Container     string
+	Image         string
+	StartDuration time.Duration
+	ReadyDuration time.Duration
+	startedAt     time.Time
+}
+
+type StartupReport []ContainerTiming
+
+// StartupReport returns the timings of the containers in the network, slowest to start first
+func (n *NetworkOfDockerContainers) StartupReport() StartupReport {
+	report := append(StartupReport{}, n.timings...)
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].StartDuration > report[j].StartDuration
+	})
+	return report
+}
+
+func (r StartupReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-20s %-40s %12s %12s\n", "CONTAINER", "IMAGE", "STARTED IN", "READY AFTER")
+	for _, timing := range r {
+		fmt.Fprintf(&sb, "%-20s %-40s %12s %12s\n", timing.Container, timing.Image,
+			timing.StartDuration.Round(time.Millisecond), timing.ReadyDuration.Round(time.Millisecond))
+	}
+	return sb.String()
+}
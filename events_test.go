@@ -0,0 +1,41 @@
+package testcontainernetwork
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogEventListener_OnEvent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	listener := SlogEventListener{Logger: slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))}
+
+	listener.OnEvent(Event{Type: EventContainerStarted, Container: "sqs", Image: "softwaremill/elasticmq", Duration: 1500 * time.Millisecond})
+	listener.OnEvent(Event{Type: EventContainerFailed, Container: "sqs", Err: errors.New("boom")})
+
+	assert.Equal(t, "level=INFO msg=\"container started\" duration=1.5s container=sqs image=softwaremill/elasticmq\n"+
+		"level=ERROR msg=\"container failed\" duration=0s container=sqs error=boom\n", buf.String())
+}
+
+func TestNetworkOfDockerContainers_StartupReport(t *testing.T) {
+	network := NetworkOfDockerContainers{timings: []ContainerTiming{
+		{Container: "sqs", StartDuration: time.Second},
+		{Container: "dynamodb", StartDuration: 3 * time.Second},
+		{Container: "lambda", StartDuration: 2 * time.Second},
+	}}
+
+	report := network.StartupReport()
+
+	assert.Equal(t, []string{"dynamodb", "lambda", "sqs"}, []string{report[0].Container, report[1].Container, report[2].Container})
+	assert.Equal(t, "sqs", network.timings[0].Container)
+}
@@ -0,0 +1,226 @@
+package testcontainernetwork
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"io"
+	"strings"
+	"sync"
+)
+
+type FakeOperation string
+
+const (
+	FakeCreateNetwork   FakeOperation = "create network"
+	FakeRemoveNetwork   FakeOperation = "remove network"
+	FakeCreateContainer FakeOperation = "create container"
+	FakeStartContainer  FakeOperation = "start container"
+	FakeStopContainer   FakeOperation = "stop container"
+	FakeCopyToContainer FakeOperation = "copy to container"
+	FakeExecInContainer FakeOperation = "exec in container"
+	FakePullImage       FakeOperation = "pull image"
+	FakeLoadImage       FakeOperation = "load image"
+)
+
+// FakeCall records a call made to a FakeContainerProvider or one of its containers; Target is the name of the network
+// or container or the image, and Detail describes the arguments, such as the paths of a copied file
+type FakeCall struct {
+	Operation FakeOperation
+	Target    string
+	Detail    string
+}
+
+func (c FakeCall) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s", c.Operation, c.Target)
+	}
+	return fmt.Sprintf("%s %s %s", c.Operation, c.Target, c.Detail)
+}
+
+// FakeContainerProvider is an in-memory ContainerProvider that records the calls made to it, and to the containers it
+// creates, so that the orchestration of a network can be tested without a Docker daemon.  The zero value is ready to
+// use and has no images locally
+type FakeContainerProvider struct {
+	// Images are the images that exist locally
+	Images map[string]bool
+	// Files are the contents of files in the containers, keyed by container name and then path, returned when copying
+	// files from them
+	Files map[string]map[string]string
+	// Logs are the logs of the containers, keyed by container name
+	Logs map[string]string
+	// ExecOutputs are the outputs of commands executed in the containers, keyed by container name and then command,
+	// with its arguments joined by spaces
+	ExecOutputs map[string]map[string]string
+	// Runtime is the host runtime that the provider reports
+	Runtime HostRuntime
+
+	mu       sync.Mutex
+	calls    []FakeCall
+	requests []testcontainers.ContainerRequest
+	failures map[string]error
+	networks int
+}
+
+// FailOn makes the provider return err when operation is performed on target
+func (p *FakeContainerProvider) FailOn(operation FakeOperation, target string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures == nil {
+		p.failures = map[string]error{}
+	}
+	p.failures[string(operation)+" "+target] = err
+}
+
+// Calls returns the calls made to the provider and its containers, in the order they were made
+func (p *FakeContainerProvider) Calls() []FakeCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]FakeCall{}, p.calls...)
+}
+
+// Requests returns the requests that containers were created from, in the order they were made
+func (p *FakeContainerProvider) Requests() []testcontainers.ContainerRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]testcontainers.ContainerRequest{}, p.requests...)
+}
+
+// Request returns the request that the named container was created from
+func (p *FakeContainerProvider) Request(name string) (testcontainers.ContainerRequest, bool) {
+	for _, req := range p.Requests() {
+		if req.Name == name {
+			return req, true
+		}
+	}
+	return testcontainers.ContainerRequest{}, false
+}
+
+func (p *FakeContainerProvider) record(operation FakeOperation, target string, detail string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, FakeCall{Operation: operation, Target: target, Detail: detail})
+	return p.failures[string(operation)+" "+target]
+}
+
+func (p *FakeContainerProvider) CreateNetwork(context.Context) (*testcontainers.DockerNetwork, error) {
+	p.mu.Lock()
+	p.networks++
+	name := fmt.Sprintf("fake-network-%d", p.networks)
+	p.mu.Unlock()
+	if err := p.record(FakeCreateNetwork, name, ""); err != nil {
+		return nil, err
+	}
+	return &testcontainers.DockerNetwork{ID: name, Name: name, Driver: "bridge"}, nil
+}
+
+func (p *FakeContainerProvider) RemoveNetwork(_ context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	return p.record(FakeRemoveNetwork, dockerNetwork.Name, "")
+}
+
+func (p *FakeContainerProvider) CreateContainer(_ context.Context, req testcontainers.ContainerRequest) (Container, error) {
+	if err := p.record(FakeCreateContainer, req.Name, req.Image); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+	return &fakeContainer{provider: p, req: req}, nil
+}
+
+func (p *FakeContainerProvider) ImageExists(_ context.Context, image string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Images[image], nil
+}
+
+func (p *FakeContainerProvider) PullImage(_ context.Context, image string, _ io.Writer) error {
+	if err := p.record(FakePullImage, image, ""); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Images == nil {
+		p.Images = map[string]bool{}
+	}
+	p.Images[image] = true
+	return nil
+}
+
+func (p *FakeContainerProvider) LoadImage(_ context.Context, archive string) error {
+	return p.record(FakeLoadImage, archive, "")
+}
+
+func (p *FakeContainerProvider) HostRuntime(context.Context) (HostRuntime, error) {
+	return p.Runtime, nil
+}
+
+// fakeContainer is a Container created by a FakeContainerProvider
+type fakeContainer struct {
+	provider *FakeContainerProvider
+	req      testcontainers.ContainerRequest
+	started  bool
+}
+
+func (c *fakeContainer) Start(context.Context) error {
+	if err := c.provider.record(FakeStartContainer, c.req.Name, ""); err != nil {
+		return err
+	}
+	c.started = true
+	return nil
+}
+
+func (c *fakeContainer) Terminate(context.Context) error {
+	c.started = false
+	return c.provider.record(FakeStopContainer, c.req.Name, "")
+}
+
+// MappedPort maps each exposed port to a port 10000 higher, or fails if the port isn't exposed or the container
+// hasn't started
+func (c *fakeContainer) MappedPort(_ context.Context, port nat.Port) (nat.Port, error) {
+	if !c.started {
+		return "", fmt.Errorf("container %s is not running", c.req.Name)
+	}
+	for _, exposedPort := range c.req.ExposedPorts {
+		if nat.Port(exposedPort) == port {
+			return nat.NewPort(port.Proto(), fmt.Sprint(port.Int()+10000))
+		}
+	}
+	return "", fmt.Errorf("port %s is not exposed by container %s", port, c.req.Name)
+}
+
+func (c *fakeContainer) Exec(_ context.Context, cmd []string, _ ...tcexec.ProcessOption) (int, io.Reader, error) {
+	if err := c.provider.record(FakeExecInContainer, c.req.Name, strings.Join(cmd, " ")); err != nil {
+		return 1, strings.NewReader(err.Error()), nil
+	}
+	c.provider.mu.Lock()
+	defer c.provider.mu.Unlock()
+	return 0, strings.NewReader(c.provider.ExecOutputs[c.req.Name][strings.Join(cmd, " ")]), nil
+}
+
+func (c *fakeContainer) Logs(context.Context) (io.ReadCloser, error) {
+	c.provider.mu.Lock()
+	defer c.provider.mu.Unlock()
+	return io.NopCloser(strings.NewReader(c.provider.Logs[c.req.Name])), nil
+}
+
+func (c *fakeContainer) CopyFileToContainer(_ context.Context, hostFilePath string, containerFilePath string, fileMode int64) error {
+	return c.provider.record(FakeCopyToContainer, c.req.Name, fmt.Sprintf("%s -> %s (%o)", hostFilePath, containerFilePath, fileMode))
+}
+
+func (c *fakeContainer) CopyToContainer(_ context.Context, fileContent []byte, containerFilePath string, fileMode int64) error {
+	return c.provider.record(FakeCopyToContainer, c.req.Name, fmt.Sprintf("%d bytes -> %s (%o)", len(fileContent), containerFilePath, fileMode))
+}
+
+func (c *fakeContainer) CopyFileFromContainer(_ context.Context, filePath string) (io.ReadCloser, error) {
+	c.provider.mu.Lock()
+	defer c.provider.mu.Unlock()
+	content, ok := c.provider.Files[c.req.Name][filePath]
+	if !ok {
+		return nil, fmt.Errorf("no such file %s in container %s", filePath, c.req.Name)
+	}
+	return io.NopCloser(bytes.NewBufferString(content)), nil
+}
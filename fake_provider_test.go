@@ -0,0 +1,317 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func fakeNetwork(provider *FakeContainerProvider, dockerContainers ...StartableDockerContainer) NetworkOfDockerContainers {
+	network := NetworkOfDockerContainers{}.WithProvider(provider).WithPullProgress(io.Discard)
+	for _, dockerContainer := range dockerContainers {
+		network = network.WithDockerContainer(dockerContainer)
+	}
+	return network
+}
+
+func callsAsStrings(calls []FakeCall) []string {
+	var s []string
+	for _, call := range calls {
+		s = append(s, call.String())
+	}
+	return s
+}
+
+func TestNetworkOfDockerContainers_StartAndStopWithFakeProvider(t *testing.T) {
+	provider := &FakeContainerProvider{Images: map[string]bool{"softwaremill/elasticmq": true}}
+	network := fakeNetwork(provider,
+		&SqsDockerContainer{Config: SqsDockerContainerConfig{Hostname: "sqs", Port: 9324, ConfigFile: "elasticmq.conf"}},
+		&DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}},
+	)
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+
+	assert.Equal(t, []string{
+		"pull image amazon/dynamodb-local",
+		"create network fake-network-1",
+		"create container sqs softwaremill/elasticmq",
+		"copy to container sqs elasticmq.conf -> /opt/elasticmq.conf (644)",
+		"start container sqs",
+		"create container dynamodb amazon/dynamodb-local",
+		"start container dynamodb",
+		"stop container sqs",
+		"stop container dynamodb",
+		"remove network fake-network-1",
+	}, callsAsStrings(provider.Calls()))
+}
+
+func TestNetworkOfDockerContainers_StopsEveryContainerWhenOneFailsToStop(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider,
+		&PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "postgres", Port: 5432}},
+		&DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}},
+		&WiremockDockerContainer{Config: WiremockDockerContainerConfig{Hostname: "wiremock", Port: 8080}},
+	)
+	assert.NoError(t, network.StartWithDelay(0))
+	provider.FailOn(FakeStopContainer, "postgres", errors.New("container is paused"))
+	provider.FailOn(FakeStopContainer, "dynamodb", errors.New("device or resource busy"))
+
+	err := network.Stop()
+
+	assert.ErrorContains(t, err, "stopping docker container: container is paused")
+	assert.ErrorContains(t, err, "stopping docker container: device or resource busy")
+	calls := callsAsStrings(provider.Calls())
+	assert.Contains(t, calls, "stop container wiremock")
+	assert.Contains(t, calls, "remove network fake-network-1")
+}
+
+func TestNetworkOfDockerContainers_StopIsSafeAfterFailedStartAndWithoutStart(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	provider.FailOn(FakeStartContainer, "dynamodb", errors.New("no space left on device"))
+	network := fakeNetwork(provider,
+		&PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "postgres", Port: 5432}},
+		&DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}},
+	).WithSubNetwork("private")
+	assert.Error(t, network.StartWithDelay(0))
+	rolledBack := len(provider.Calls())
+
+	assert.NoError(t, network.Stop())
+	assert.Len(t, provider.Calls(), rolledBack)
+
+	neverStarted := &FakeContainerProvider{}
+	neverStartedNetwork := fakeNetwork(neverStarted).WithSubNetwork("private")
+	assert.NoError(t, neverStartedNetwork.Stop())
+	assert.Empty(t, neverStarted.Calls())
+}
+
+type closableFakeContainerProvider struct {
+	*FakeContainerProvider
+	closed bool
+}
+
+func (p *closableFakeContainerProvider) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestNetworkOfDockerContainers_ClosesProviderWhenStopped(t *testing.T) {
+	provider := &closableFakeContainerProvider{FakeContainerProvider: &FakeContainerProvider{}}
+	network := NetworkOfDockerContainers{}.WithProvider(provider).WithPullProgress(io.Discard).
+		WithDockerContainer(&WiremockDockerContainer{Config: WiremockDockerContainerConfig{Hostname: "wiremock", Port: 8080}})
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.False(t, provider.closed)
+
+	assert.NoError(t, network.Stop())
+
+	assert.True(t, provider.closed)
+}
+
+func TestNetworkOfDockerContainers_RollsBackWhenContainerFailsToStart(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	provider.FailOn(FakeStartContainer, "dynamodb", errors.New("no space left on device"))
+	var containerFailures, networkFailures []error
+	dynamoDbContainer := &DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}}
+	dynamoDbContainer.OnFailure(func(_ context.Context, err error) {
+		containerFailures = append(containerFailures, err)
+	})
+	network := fakeNetwork(provider,
+		&PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "postgres", Port: 5432}},
+		dynamoDbContainer,
+		&WiremockDockerContainer{Config: WiremockDockerContainerConfig{Hostname: "wiremock", Port: 8080}},
+	).WithHooks(LifecycleHooks{OnFailure: []FailureHook{func(_ context.Context, err error) {
+		networkFailures = append(networkFailures, err)
+	}}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "no space left on device")
+	assert.Len(t, containerFailures, 1)
+	assert.Len(t, networkFailures, 1)
+	calls := callsAsStrings(provider.Calls())
+	assert.Equal(t, []string{
+		"start container dynamodb",
+		"stop container dynamodb",
+		"stop container postgres",
+		"remove network fake-network-1",
+	}, calls[len(calls)-4:])
+	assert.NotContains(t, calls, "create container wiremock wiremock/wiremock")
+}
+
+func TestNetworkOfDockerContainers_RunsHooksInOrder(t *testing.T) {
+	var order []string
+	hook := func(name string) LifecycleHook {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	sqsContainer := &SqsDockerContainer{Config: SqsDockerContainerConfig{Hostname: "sqs", Port: 9324}}
+	sqsContainer.BeforeStart(hook("sqs before start"))
+	sqsContainer.AfterReady(hook("sqs after ready"))
+	sqsContainer.BeforeStop(hook("sqs before stop"))
+	network := fakeNetwork(&FakeContainerProvider{}, sqsContainer).WithHooks(LifecycleHooks{
+		BeforeStart: []LifecycleHook{hook("network before start")},
+		AfterReady:  []LifecycleHook{hook("network after ready")},
+		BeforeStop:  []LifecycleHook{hook("network before stop")},
+	})
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+
+	assert.Equal(t, []string{
+		"network before start",
+		"sqs before start",
+		"sqs after ready",
+		"network after ready",
+		"network before stop",
+		"sqs before stop",
+	}, order)
+}
+
+func TestNetworkOfDockerContainers_PullNeverFailsWhenImagesAreMissing(t *testing.T) {
+	provider := &FakeContainerProvider{Images: map[string]bool{"postgres:13": true}}
+	network := fakeNetwork(provider,
+		&PostgresDockerContainer{},
+		&FlywayDockerContainer{},
+	).WithPullPolicy(PullNever)
+
+	err := network.StartWithDelay(0)
+
+	assert.EqualError(t, err, "pulling images: images not available locally and pull policy is never: flyway/flyway")
+	assert.Empty(t, provider.Calls())
+}
+
+func TestLambdaDockerContainer_TranslatesConfigToContainerRequest(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable:  "main",
+		Environment: map[string]string{"API_ENDPOINT": "http://wiremock:8080", "AWS_REGION": "us-east-1"},
+	}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, ok := provider.Request("lambda")
+	assert.True(t, ok)
+	assert.Equal(t, "lambda", req.Hostname)
+	assert.Equal(t, []string{"fake-network-1"}, req.Networks)
+	assert.Equal(t, "http://wiremock:8080", req.Env["API_ENDPOINT"])
+	assert.Equal(t, "us-east-1", req.Env["AWS_REGION"])
+	assert.Equal(t, []string{"8080/tcp"}, req.ExposedPorts)
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container lambda main -> /var/runtime/bootstrap (755)")
+}
+
+func TestLambdaDockerContainer_RunsLegacyGo1xRuntime(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable: "main",
+		Runtime:    LambdaRuntimeGo1x,
+	}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, "lambci/lambda:go1.x", req.Image)
+	assert.Equal(t, []string{"9001/tcp"}, req.ExposedPorts)
+	assert.Equal(t, "1", req.Env["DOCKER_LAMBDA_STAY_OPEN"])
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container lambda main -> /var/task/handler (755)")
+}
+
+func TestLambdaDockerContainer_ConfiguresFunctionLikeAws(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable:      "main",
+		FunctionName:    "orders",
+		Timeout:         1500 * time.Millisecond,
+		MemorySize:      256,
+		Architecture:    LambdaArchitectureArm64,
+		FunctionVersion: "7",
+		Region:          "us-west-2",
+	}}
+	network := fakeNetwork(provider, lambdaContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("orders")
+	assert.Equal(t, "2", req.Env["AWS_LAMBDA_FUNCTION_TIMEOUT"])
+	assert.Equal(t, "256", req.Env["AWS_LAMBDA_FUNCTION_MEMORY_SIZE"])
+	assert.Equal(t, "7", req.Env["AWS_LAMBDA_FUNCTION_VERSION"])
+	assert.Equal(t, "us-west-2", req.Env["AWS_REGION"])
+	assert.Equal(t, "us-west-2", req.Env["AWS_DEFAULT_REGION"])
+	assert.Equal(t, "linux/arm64", req.ImagePlatform)
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Equal(t, int64(256*1024*1024), hostConfig.Memory)
+	assert.Equal(t, "arn:aws:lambda:us-west-2:000000000000:function:orders", lambdaContainer.FunctionArn())
+	assert.Equal(t, "arm64", lambdaContainer.lambdaBuild().goarch)
+}
+
+func TestLambdaDockerContainer_DefaultsToAwsSettings(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main"}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, "3", req.Env["AWS_LAMBDA_FUNCTION_TIMEOUT"])
+	assert.Equal(t, "128", req.Env["AWS_LAMBDA_FUNCTION_MEMORY_SIZE"])
+	assert.Equal(t, "$LATEST", req.Env["AWS_LAMBDA_FUNCTION_VERSION"])
+	assert.Equal(t, "eu-west-1", req.Env["AWS_REGION"])
+	assert.Empty(t, req.ImagePlatform)
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Zero(t, hostConfig.Memory)
+}
+
+func TestLambdaDockerContainer_RejectsArm64OnLegacyGo1xRuntime(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable:   "main",
+		Runtime:      LambdaRuntimeGo1x,
+		Architecture: LambdaArchitectureArm64,
+	}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "the go1.x Lambda runtime only supports the x86_64 architecture")
+}
+
+func TestNetworkOfDockerContainers_RunsSeveralNamedLambdaFunctions(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider,
+		&LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "orders", FunctionName: "orders"}},
+		&LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "payments", FunctionName: "payments", Handler: "bootstrap"}},
+	)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	ordersReq, _ := provider.Request("orders")
+	assert.Equal(t, []string{"handler"}, ordersReq.Cmd)
+	assert.Equal(t, "orders", ordersReq.Env["AWS_LAMBDA_FUNCTION_NAME"])
+	paymentsReq, _ := provider.Request("payments")
+	assert.Equal(t, []string{"bootstrap"}, paymentsReq.Cmd)
+	paymentsLambda, err := network.Lambda("payments")
+	assert.NoError(t, err)
+	assert.Equal(t, "payments", paymentsLambda.Config.Hostname)
+	_, err = network.Lambda("refunds")
+	assert.EqualError(t, err, "no Lambda function named refunds in the network")
+}
+
+func TestLambdaDockerContainer_InstallsLegacyExecutableAsHandler(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable: "main",
+		Handler:    "orders",
+		Runtime:    LambdaRuntimeGo1x,
+	}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, []string{"orders"}, req.Cmd)
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container lambda main -> /var/task/orders (755)")
+}
@@ -2,17 +2,22 @@ package testcontainernetwork
 
 import (
 	"context"
-	"fmt"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/testcontainers/testcontainers-go"
+	"io/fs"
 )
 
+const flywayImage = "flyway/flyway"
+
 type FlywayDockerContainerConfig struct {
 	Hostname        string
 	Port            int
 	ConfigFilesPath string
 	SqlFilesPath    string
+	// ConfigFiles and SqlFiles, if set, are copied into the container instead of mounting ConfigFilesPath and
+	// SqlFilesPath, and can be embedded in the test binary
+	ConfigFiles fs.FS
+	SqlFiles    fs.FS
 }
 
 type FlywayDockerContainer struct {
@@ -20,43 +25,24 @@ type FlywayDockerContainer struct {
 	Config FlywayDockerContainerConfig
 }
 
+func (c *FlywayDockerContainer) Image() string {
+	return flywayImage
+}
+
 func (c *FlywayDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 	req := testcontainers.ContainerRequest{
-		Image:    "flyway/flyway",
+		Image:    c.Image(),
 		Name:     c.Config.Hostname,
 		Hostname: c.Config.Hostname,
 		Networks: []string{dockerNetwork.Name},
 		HostConfigModifier: func(config *container.HostConfig) {
 			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
-			config.Mounts = []mount.Mount{
-				{
-					Type:     mount.TypeBind,
-					Source:   c.Config.SqlFilesPath,
-					Target:   "/flyway/sql",
-					ReadOnly: true,
-				},
-				{
-					Type:     mount.TypeBind,
-					Source:   c.Config.ConfigFilesPath,
-					Target:   "/flyway/conf",
-					ReadOnly: true,
-				},
-			}
 		},
 		Entrypoint: []string{"flyway", "migrate"},
 	}
+	c.mountDirectory(&req, c.Config.SqlFiles, c.Config.SqlFilesPath, "/flyway/sql")
+	c.mountDirectory(&req, c.Config.ConfigFiles, c.Config.ConfigFilesPath, "/flyway/conf")
 
-	var err error
-	if c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	}); err != nil {
-		return fmt.Errorf("creating container: %w", err)
-	}
-
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
-	}
-	return nil
+	return c.createAndStart(ctx, req)
 }
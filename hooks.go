@@ -0,0 +1,81 @@
+package testcontainernetwork
+
+import (
+	"context"
+)
+
+// LifecycleHook is run at a point in the lifecycle of a container or of the network, for example to create the
+// tables in DynamoDB once it is ready
+type LifecycleHook func(ctx context.Context) error
+
+// FailureHook is run with the error that caused starting a container or the network to fail
+type FailureHook func(ctx context.Context, err error)
+
+// LifecycleHooks are run by the network: BeforeStart before a container (or the first container) is started,
+// AfterReady once all the containers have started and the start delay has elapsed, BeforeStop before a container
+// (or the first container) is stopped, and OnFailure when starting fails
+type LifecycleHooks struct {
+	BeforeStart []LifecycleHook
+	AfterReady  []LifecycleHook
+	BeforeStop  []LifecycleHook
+	OnFailure   []FailureHook
+}
+
+func (h *LifecycleHooks) append(hooks LifecycleHooks) {
+	h.BeforeStart = append(h.BeforeStart, hooks.BeforeStart...)
+	h.AfterReady = append(h.AfterReady, hooks.AfterReady...)
+	h.BeforeStop = append(h.BeforeStop, hooks.BeforeStop...)
+	h.OnFailure = append(h.OnFailure, hooks.OnFailure...)
+}
+
+// hookedDockerContainer is implemented by containers that promote DockerContainer
+type hookedDockerContainer interface {
+	lifecycleHooks() *LifecycleHooks
+}
+
+func (c *DockerContainer) lifecycleHooks() *LifecycleHooks {
+	return &c.hooks
+}
+
+func (c *DockerContainer) BeforeStart(hooks ...LifecycleHook) {
+	c.hooks.BeforeStart = append(c.hooks.BeforeStart, hooks...)
+}
+
+func (c *DockerContainer) AfterReady(hooks ...LifecycleHook) {
+	c.hooks.AfterReady = append(c.hooks.AfterReady, hooks...)
+}
+
+func (c *DockerContainer) BeforeStop(hooks ...LifecycleHook) {
+	c.hooks.BeforeStop = append(c.hooks.BeforeStop, hooks...)
+}
+
+func (c *DockerContainer) OnFailure(hooks ...FailureHook) {
+	c.hooks.OnFailure = append(c.hooks.OnFailure, hooks...)
+}
+
+func (n NetworkOfDockerContainers) WithHooks(hooks LifecycleHooks) NetworkOfDockerContainers {
+	n.hooks.append(hooks)
+	return n
+}
+
+func hooksOf(dockerContainer StartableDockerContainer) LifecycleHooks {
+	if hooked, ok := dockerContainer.(hookedDockerContainer); ok {
+		return *hooked.lifecycleHooks()
+	}
+	return LifecycleHooks{}
+}
+
+func runHooks(ctx context.Context, hooks []LifecycleHook) error {
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runFailureHooks(ctx context.Context, hooks []FailureHook, err error) {
+	for _, hook := range hooks {
+		hook(ctx, err)
+	}
+}
@@ -0,0 +1,36 @@
+package testcontainernetwork
+
+import (
+	"fmt"
+	"github.com/testcontainers/testcontainers-go"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// HostInternal is the hostname by which the containers in the network can reach the ports on the test host that the
+// network has been given with WithHostAccessPorts
+const HostInternal = testcontainers.HostInternal
+
+// WithHostAccessPorts makes the given ports on the test host, for example that of an httptest.Server started by the
+// test, reachable from every container in the network at HostInternal.  Whatever is listening on the ports must be
+// started before the network is
+func (n NetworkOfDockerContainers) WithHostAccessPorts(ports ...int) NetworkOfDockerContainers {
+	n.hostAccessPorts = append(n.hostAccessPorts, ports...)
+	return n
+}
+
+// HostInternalUrl rewrites a URL on the test host, such as the URL of an httptest.Server, to the URL by which the
+// containers in the network can reach it, returning the port to pass to WithHostAccessPorts
+func HostInternalUrl(hostUrl string) (string, int, error) {
+	u, err := url.Parse(hostUrl)
+	if err != nil {
+		return "", 0, fmt.Errorf("parsing url: %w", err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", 0, fmt.Errorf("url %s has no port", hostUrl)
+	}
+	u.Host = net.JoinHostPort(HostInternal, u.Port())
+	return u.String(), port, nil
+}
@@ -0,0 +1,34 @@
+package testcontainernetwork
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHostInternalUrl(t *testing.T) {
+	internalUrl, port, err := HostInternalUrl("http://127.0.0.1:45678/api?x=1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://host.testcontainers.internal:45678/api?x=1", internalUrl)
+	assert.Equal(t, 45678, port)
+}
+
+func TestHostInternalUrl_WithoutPort(t *testing.T) {
+	_, _, err := HostInternalUrl("http://localhost/api")
+
+	assert.Error(t, err)
+}
+
+func TestNetworkOfDockerContainers_GivesEveryContainerAccessToHostPorts(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider,
+		&LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main"}},
+		&WiremockDockerContainer{Config: WiremockDockerContainerConfig{Hostname: "wiremock", Port: 8080}},
+	).WithHostAccessPorts(45678)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	for _, req := range provider.Requests() {
+		assert.Equal(t, []int{45678}, req.HostAccessPorts)
+	}
+}
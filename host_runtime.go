@@ -0,0 +1,135 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/testcontainers/testcontainers-go"
+	"os"
+	"strings"
+)
+
+const (
+	executableFileMode = 0o755
+	configFileMode     = 0o644
+)
+
+// HostRuntime describes the container runtime that the network runs on
+type HostRuntime struct {
+	Podman   bool
+	Rootless bool
+	SELinux  bool
+}
+
+// MountStrategy is how host directories, such as Flyway's SQL files and Wiremock's mappings, get into containers
+type MountStrategy string
+
+const (
+	// MountBind bind-mounts the host directory read-only, which is the default with rootful Docker
+	MountBind MountStrategy = "bind"
+	// MountBindWithSELinuxLabel bind-mounts the host directory read-only with a shared SELinux label, so that
+	// containers are permitted to read it on hosts that enforce SELinux
+	MountBindWithSELinuxLabel MountStrategy = "bind-selinux"
+	// MountCopy copies the host directory into the container before it starts, which works when bind mounts don't,
+	// such as on Podman or rootless Docker, where files are owned by a different user inside the container, or when
+	// the daemon can't see the host's filesystem
+	MountCopy MountStrategy = "copy"
+)
+
+// MountStrategy returns the strategy that works on the runtime
+func (r HostRuntime) MountStrategy() MountStrategy {
+	switch {
+	case r.Podman || r.Rootless:
+		return MountCopy
+	case r.SELinux:
+		return MountBindWithSELinuxLabel
+	}
+	return MountBind
+}
+
+// WithMountStrategy overrides the mount strategy that the network otherwise chooses by detecting the host runtime
+func (n NetworkOfDockerContainers) WithMountStrategy(strategy MountStrategy) NetworkOfDockerContainers {
+	n.mountStrategy = strategy
+	return n
+}
+
+func (n *NetworkOfDockerContainers) resolveMountStrategy(ctx context.Context) error {
+	if n.mountStrategy != "" {
+		return nil
+	}
+	hostRuntime, err := n.containerProvider().HostRuntime(ctx)
+	if err != nil {
+		return fmt.Errorf("detecting host runtime: %w", err)
+	}
+	n.mountStrategy = hostRuntime.MountStrategy()
+	return nil
+}
+
+func (p *dockerProvider) HostRuntime(ctx context.Context) (HostRuntime, error) {
+	dockerClient, err := p.dockerClient(ctx)
+	if err != nil {
+		return HostRuntime{}, err
+	}
+	var hostRuntime HostRuntime
+	version, err := dockerClient.ServerVersion(ctx)
+	if err != nil {
+		return HostRuntime{}, fmt.Errorf("getting server version: %w", err)
+	}
+	for _, component := range version.Components {
+		if strings.Contains(strings.ToLower(component.Name), "podman") {
+			hostRuntime.Podman = true
+		}
+	}
+	info, err := dockerClient.Info(ctx)
+	if err != nil {
+		return HostRuntime{}, fmt.Errorf("getting server info: %w", err)
+	}
+	for _, option := range info.SecurityOptions {
+		switch {
+		case strings.Contains(option, "name=rootless"):
+			hostRuntime.Rootless = true
+		case strings.Contains(option, "name=selinux"):
+			hostRuntime.SELinux = true
+		}
+	}
+	return hostRuntime, nil
+}
+
+func (c *DockerContainer) mountStrategy() MountStrategy {
+	if c.network != nil && c.network.mountStrategy != "" {
+		return c.network.mountStrategy
+	}
+	return MountBind
+}
+
+// mountHostDirectory makes the host directory source available read-only at target in the container described by req,
+// either by bind-mounting it or by copying it into the container before it starts, according to the mount strategy
+func (c *DockerContainer) mountHostDirectory(req *testcontainers.ContainerRequest, source string, target string) {
+	var addMount func(config *container.HostConfig)
+	switch c.mountStrategy() {
+	case MountCopy:
+		c.copyDirectoryOnStart(os.DirFS(source), target)
+		return
+	case MountBindWithSELinuxLabel:
+		addMount = func(config *container.HostConfig) {
+			config.Binds = append(config.Binds, fmt.Sprintf("%s:%s:ro,z", source, target))
+		}
+	default:
+		addMount = func(config *container.HostConfig) {
+			config.Mounts = append(config.Mounts, mount.Mount{
+				Type:     mount.TypeBind,
+				Source:   source,
+				Target:   target,
+				ReadOnly: true,
+			})
+		}
+	}
+	hostConfigModifier := req.HostConfigModifier
+	req.HostConfigModifier = func(config *container.HostConfig) {
+		if hostConfigModifier != nil {
+			hostConfigModifier(config)
+		}
+		addMount(config)
+	}
+}
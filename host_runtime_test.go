@@ -0,0 +1,90 @@
+package testcontainernetwork
+
+import (
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHostRuntime_MountStrategy(t *testing.T) {
+	assert.Equal(t, MountBind, HostRuntime{}.MountStrategy())
+	assert.Equal(t, MountBindWithSELinuxLabel, HostRuntime{SELinux: true}.MountStrategy())
+	assert.Equal(t, MountCopy, HostRuntime{Podman: true, SELinux: true}.MountStrategy())
+	assert.Equal(t, MountCopy, HostRuntime{Rootless: true}.MountStrategy())
+}
+
+func flywayContainerWithFiles(t *testing.T) (*FlywayDockerContainer, string, string) {
+	sqlDir, confDir := t.TempDir(), t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(sqlDir, "V1__create.sql"), []byte("create table t (id int);"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(confDir, "flyway.conf"), []byte("flyway.url=x"), 0o600))
+	return &FlywayDockerContainer{Config: FlywayDockerContainerConfig{
+		Hostname:        "flyway",
+		SqlFilesPath:    sqlDir,
+		ConfigFilesPath: confDir,
+	}}, sqlDir, confDir
+}
+
+func TestFlywayDockerContainer_BindMountsDirectoriesWithRootfulDocker(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	flywayContainer, sqlDir, confDir := flywayContainerWithFiles(t)
+	network := fakeNetwork(provider, flywayContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("flyway")
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Equal(t, []mount.Mount{
+		{Type: mount.TypeBind, Source: sqlDir, Target: "/flyway/sql", ReadOnly: true},
+		{Type: mount.TypeBind, Source: confDir, Target: "/flyway/conf", ReadOnly: true},
+	}, hostConfig.Mounts)
+	assert.Equal(t, container.NetworkMode("fake-network-1"), hostConfig.NetworkMode)
+}
+
+func TestFlywayDockerContainer_LabelsBindMountsWithSELinux(t *testing.T) {
+	provider := &FakeContainerProvider{Runtime: HostRuntime{SELinux: true}}
+	flywayContainer, sqlDir, confDir := flywayContainerWithFiles(t)
+	network := fakeNetwork(provider, flywayContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("flyway")
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Empty(t, hostConfig.Mounts)
+	assert.Equal(t, []string{sqlDir + ":/flyway/sql:ro,z", confDir + ":/flyway/conf:ro,z"}, hostConfig.Binds)
+}
+
+func TestFlywayDockerContainer_CopiesDirectoriesWithPodman(t *testing.T) {
+	provider := &FakeContainerProvider{Runtime: HostRuntime{Podman: true}}
+	flywayContainer, _, _ := flywayContainerWithFiles(t)
+	network := fakeNetwork(provider, flywayContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("flyway")
+	hostConfig := &container.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Empty(t, hostConfig.Mounts)
+	assert.Empty(t, hostConfig.Binds)
+	assert.Equal(t, []string{
+		"create network fake-network-1",
+		"create container flyway flyway/flyway",
+		"copy to container flyway 24 bytes -> /flyway/sql/V1__create.sql (644)",
+		"copy to container flyway 12 bytes -> /flyway/conf/flyway.conf (644)",
+		"start container flyway",
+	}, callsAsStrings(provider.Calls())[1:])
+}
+
+func TestNetworkOfDockerContainers_WithMountStrategyOverridesDetection(t *testing.T) {
+	provider := &FakeContainerProvider{Runtime: HostRuntime{SELinux: true}}
+	flywayContainer, _, _ := flywayContainerWithFiles(t)
+	network := fakeNetwork(provider, flywayContainer).WithMountStrategy(MountCopy)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container flyway 12 bytes -> /flyway/conf/flyway.conf (644)")
+}
@@ -0,0 +1,137 @@
+package testcontainernetwork
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/distribution/reference"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WithImageArchives sets a directory of image archives, as created by docker save, from which any images that are
+// missing locally are loaded before the containers start, allowing the network to be used on air-gapped machines.
+// Images that are in neither fail the start rather than being pulled, unless the pull policy is PullAlways
+func (n NetworkOfDockerContainers) WithImageArchives(directory string) NetworkOfDockerContainers {
+	n.imageArchives = directory
+	return n
+}
+
+// loadImagesFromArchives loads the images that are found in the archives in directory and returns those that aren't
+func loadImagesFromArchives(ctx context.Context, provider ContainerProvider, directory string, images []string, progress io.Writer, emit func(Event)) ([]string, error) {
+	archives, err := imageArchivesIn(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var notFound []string
+	loaded := map[string]bool{}
+	for _, img := range images {
+		archive, ok := archives[normalisedImageName(img)]
+		if !ok {
+			notFound = append(notFound, img)
+			continue
+		}
+		if loaded[archive] {
+			continue
+		}
+		fmt.Fprintf(progress, "%s: loading from %s\n", img, archive)
+		started := time.Now()
+		if err := provider.LoadImage(ctx, archive); err != nil {
+			return nil, err
+		}
+		loaded[archive] = true
+		emit(Event{Type: EventImageLoaded, Image: img, Duration: time.Since(started)})
+	}
+	return notFound, nil
+}
+
+// imageArchivesIn maps the normalised name of each image in the archives in directory to the archive containing it
+func imageArchivesIn(directory string) (map[string]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("reading image archive directory: %w", err)
+	}
+	archives := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isImageArchive(entry.Name()) {
+			continue
+		}
+		archive := filepath.Join(directory, entry.Name())
+		tags, err := imageArchiveRepoTags(archive)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			archives[normalisedImageName(tag)] = archive
+		}
+	}
+	return archives, nil
+}
+
+func isImageArchive(name string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageArchiveRepoTags reads the tags of the images in a docker save archive from its manifest.json
+func imageArchiveRepoTags(archive string) ([]string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("opening image archive: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(archive, ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing image archive %s: %w", archive, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("image archive %s has no manifest.json", archive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading image archive %s: %w", archive, err)
+		}
+		if header.Name != "manifest.json" {
+			continue
+		}
+		var manifest []struct {
+			RepoTags []string `json:"RepoTags"`
+		}
+		if err := json.NewDecoder(tarReader).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("decoding manifest of image archive %s: %w", archive, err)
+		}
+		var tags []string
+		for _, image := range manifest {
+			tags = append(tags, image.RepoTags...)
+		}
+		return tags, nil
+	}
+}
+
+// normalisedImageName expands an image name so that, for example, postgres and docker.io/library/postgres:latest match
+func normalisedImageName(img string) string {
+	named, err := reference.ParseDockerRef(img)
+	if err != nil {
+		return img
+	}
+	return named.String()
+}
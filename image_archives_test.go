@@ -0,0 +1,85 @@
+package testcontainernetwork
+
+import (
+	"archive/tar"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeImageArchive(t *testing.T, path string, manifest string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating archive: %v", err)
+	}
+	defer f.Close()
+	tarWriter := tar.NewWriter(f)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0o644, Size: int64(len(manifest))}); err != nil {
+		t.Fatalf("writing header: %v", err)
+	}
+	if _, err := tarWriter.Write([]byte(manifest)); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+}
+
+func TestImageArchivesIn(t *testing.T) {
+	directory := t.TempDir()
+	writeImageArchive(t, filepath.Join(directory, "postgres.tar"), `[{"RepoTags":["postgres:13"]}]`)
+	writeImageArchive(t, filepath.Join(directory, "dynamodb.tar"), `[{"RepoTags":["amazon/dynamodb-local:latest"]}]`)
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "README.md"), []byte("not an archive"), 0o644))
+
+	archives, err := imageArchivesIn(directory)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"docker.io/library/postgres:13":          filepath.Join(directory, "postgres.tar"),
+		"docker.io/amazon/dynamodb-local:latest": filepath.Join(directory, "dynamodb.tar"),
+	}, archives)
+}
+
+func TestImageArchivesIn_ArchiveWithoutManifest(t *testing.T) {
+	directory := t.TempDir()
+	f, _ := os.Create(filepath.Join(directory, "empty.tar"))
+	assert.NoError(t, tar.NewWriter(f).Close())
+	f.Close()
+
+	_, err := imageArchivesIn(directory)
+
+	assert.Error(t, err)
+}
+
+func TestNormalisedImageName(t *testing.T) {
+	assert.Equal(t, "docker.io/amazon/dynamodb-local:latest", normalisedImageName("amazon/dynamodb-local"))
+	assert.Equal(t, normalisedImageName("docker.io/library/postgres:13"), normalisedImageName("postgres:13"))
+}
+
+func TestNetworkOfDockerContainers_PullAlwaysLeavesImagesLoadedFromArchives(t *testing.T) {
+	directory := t.TempDir()
+	writeImageArchive(t, filepath.Join(directory, "wiremock.tar"), `[{"RepoTags": ["wiremock/wiremock:latest"]}]`)
+	provider := &FakeContainerProvider{Images: map[string]bool{"postgres:13": true}}
+	network := fakeNetwork(provider, &WiremockDockerContainer{}, &PostgresDockerContainer{}).
+		WithImageArchives(directory).
+		WithPullPolicy(PullAlways)
+
+	assert.NoError(t, network.Pull(context.Background()))
+
+	assert.Equal(t, []string{"load image " + filepath.Join(directory, "wiremock.tar"), "pull image postgres:13"}, callsAsStrings(provider.Calls()))
+}
+
+func TestNetworkOfDockerContainers_ListsImagesMissingLocallyAndFromArchivesWithoutPullingThem(t *testing.T) {
+	directory := t.TempDir()
+	writeImageArchive(t, filepath.Join(directory, "wiremock.tar"), `[{"RepoTags": ["wiremock/wiremock:latest"]}]`)
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &WiremockDockerContainer{}, &PostgresDockerContainer{}, &DynamoDbDockerContainer{}).
+		WithImageArchives(directory)
+
+	err := network.Pull(context.Background())
+
+	assert.EqualError(t, err, "images not available locally or in image archives in "+directory+": postgres:13, amazon/dynamodb-local")
+	assert.Equal(t, []string{"load image " + filepath.Join(directory, "wiremock.tar")}, callsAsStrings(provider.Calls()))
+}
@@ -0,0 +1,167 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PullableDockerContainer is implemented by containers that can report the image they run, allowing the
+// network to pull it ahead of starting the containers
+type PullableDockerContainer interface {
+	Image() string
+}
+
+type PullPolicy string
+
+const (
+	PullIfMissing PullPolicy = "if-missing"
+	PullAlways    PullPolicy = "always"
+	PullNever     PullPolicy = "never"
+)
+
+func ParsePullPolicy(policy string) (PullPolicy, error) {
+	switch p := PullPolicy(policy); p {
+	case PullIfMissing, PullAlways, PullNever:
+		return p, nil
+	case "":
+		return PullIfMissing, nil
+	}
+	return "", fmt.Errorf("unknown pull policy %q, expected one of %s, %s or %s", policy, PullIfMissing, PullAlways, PullNever)
+}
+
+const pullProgressInterval = 2 * time.Second
+
+func (n NetworkOfDockerContainers) WithPullPolicy(policy PullPolicy) NetworkOfDockerContainers {
+	n.pullPolicy = policy
+	return n
+}
+
+// WithPullProgress sets where progress of image pulls is written, such as os.Stdout; by default it is discarded, so
+// that it doesn't fill the output of go test, and the EventImagePulled and EventImageLoaded events report the pulls
+func (n NetworkOfDockerContainers) WithPullProgress(w io.Writer) NetworkOfDockerContainers {
+	n.pullProgress = w
+	return n
+}
+
+// Images returns the distinct images used by the containers in the network, in the order they were added
+func (n *NetworkOfDockerContainers) Images() []string {
+	var images []string
+	seen := map[string]bool{}
+	for _, dockerContainer := range n.dockerContainers {
+		pullable, ok := dockerContainer.(PullableDockerContainer)
+		if !ok || pullable.Image() == "" || seen[pullable.Image()] {
+			continue
+		}
+		seen[pullable.Image()] = true
+		images = append(images, pullable.Image())
+	}
+	return images
+}
+
+// Pull concurrently pulls the images used by the containers in the network according to the network's pull policy,
+// so that it can be called from TestMain to avoid the first scenario paying for the image downloads
+func (n *NetworkOfDockerContainers) Pull(ctx context.Context) error {
+	policy := n.pullPolicy
+	if policy == "" {
+		policy = PullIfMissing
+	}
+	progress := n.pullProgress
+	if progress == nil {
+		progress = io.Discard
+	}
+
+	provider := n.containerProvider()
+	var missing []string
+	for _, img := range n.Images() {
+		exists, err := provider.ImageExists(ctx, img)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, img)
+		}
+	}
+	loaded := map[string]bool{}
+	if n.imageArchives != "" && len(missing) > 0 {
+		notFound, err := loadImagesFromArchives(ctx, provider, n.imageArchives, missing, progress, n.emit)
+		if err != nil {
+			return err
+		}
+		for _, img := range missing {
+			loaded[img] = !slices.Contains(notFound, img)
+		}
+		missing = notFound
+	}
+	// the archives are there because images can't be pulled, so those missing from them aren't either
+	if n.imageArchives != "" && policy != PullAlways && len(missing) > 0 {
+		return fmt.Errorf("images not available locally or in image archives in %s: %s", n.imageArchives, strings.Join(missing, ", "))
+	}
+	if policy == PullNever && len(missing) > 0 {
+		return fmt.Errorf("images not available locally and pull policy is %s: %s", policy, strings.Join(missing, ", "))
+	}
+
+	// images loaded from the archives are left as they are, since the archives are there to avoid pulling them
+	toPull := missing
+	if policy == PullAlways {
+		toPull = nil
+		for _, img := range n.Images() {
+			if !loaded[img] {
+				toPull = append(toPull, img)
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(toPull))
+	writer := &syncWriter{w: progress}
+	for i, img := range toPull {
+		wg.Add(1)
+		go func(i int, img string) {
+			defer wg.Done()
+			started := time.Now()
+			if errs[i] = pullImage(ctx, provider, img, writer); errs[i] == nil {
+				n.emit(Event{Type: EventImagePulled, Image: img, Duration: time.Since(started)})
+			}
+		}(i, img)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func pullImage(ctx context.Context, provider ContainerProvider, img string, progress io.Writer) error {
+	started := time.Now()
+	fmt.Fprintf(progress, "%s: pulling\n", img)
+	if err := provider.PullImage(ctx, img, progress); err != nil {
+		return err
+	}
+	fmt.Fprintf(progress, "%s: pulled in %s\n", img, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+func downloadProgress(layers map[string]*jsonmessage.JSONProgress) string {
+	var current, total int64
+	for _, layer := range layers {
+		current += layer.Current
+		total += layer.Total
+	}
+	return fmt.Sprintf("%s/%s of %d layers", units.HumanSize(float64(current)), units.HumanSize(float64(total)), len(layers))
+}
+
+// syncWriter serialises writes from the concurrent pulls so that progress lines don't interleave
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
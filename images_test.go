@@ -0,0 +1,61 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestParsePullPolicy(t *testing.T) {
+	for input, expected := range map[string]PullPolicy{
+		"":           PullIfMissing,
+		"if-missing": PullIfMissing,
+		"always":     PullAlways,
+		"never":      PullNever,
+	} {
+		policy, err := ParsePullPolicy(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+}
+
+func TestParsePullPolicy_Error(t *testing.T) {
+	_, err := ParsePullPolicy("sometimes")
+
+	assert.Error(t, err)
+}
+
+func TestNetworkOfDockerContainers_Images(t *testing.T) {
+	network := NetworkOfDockerContainers{}.
+		WithDockerContainer(&WiremockDockerContainer{}).
+		WithDockerContainer(&PostgresDockerContainer{}).
+		WithDockerContainer(&WiremockDockerContainer{})
+
+	assert.Equal(t, []string{"wiremock/wiremock", "postgres:13"}, network.Images())
+}
+
+func TestNetworkOfDockerContainers_PullWritesNoProgressUnlessAskedTo(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	assert.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+	var pulled []string
+	network := NetworkOfDockerContainers{}.
+		WithProvider(&FakeContainerProvider{}).
+		WithDockerContainer(&WiremockDockerContainer{}).
+		WithEventListener(EventListenerFunc(func(event Event) {
+			pulled = append(pulled, event.Image)
+		}))
+
+	assert.NoError(t, network.Pull(context.Background()))
+	writer.Close()
+	os.Stdout = stdout
+
+	written, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Empty(t, string(written))
+	assert.Equal(t, []string{"wiremock/wiremock"}, pulled)
+}
@@ -6,59 +6,357 @@ import (
 	"fmt"
 	"github.com/docker/docker/api/types/container"
 	"github.com/testcontainers/testcontainers-go"
+	"math"
+	"path"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LambdaRuntime is the runtime that the Lambda container runs the executable on
+type LambdaRuntime string
+
+const (
+	// LambdaRuntimeProvidedAl2023 is the OS-only runtime that AWS requires for Go functions, which runs the executable
+	// as the function's bootstrap under the AWS Lambda Runtime Interface Emulator
+	LambdaRuntimeProvidedAl2023 LambdaRuntime = "provided.al2023"
+	// LambdaRuntimeGo1x is the deprecated go1.x runtime, emulated by the unmaintained lambci/lambda image, which is kept
+	// for functions that haven't moved to provided.al2023
+	LambdaRuntimeGo1x LambdaRuntime = "go1.x"
+)
+
+// LambdaArchitecture is the instruction set architecture of the function
+type LambdaArchitecture string
+
+const (
+	LambdaArchitectureX86_64 LambdaArchitecture = "x86_64"
+	LambdaArchitectureArm64  LambdaArchitecture = "arm64"
+)
+
+const (
+	lambdaImage       = "public.ecr.aws/lambda/provided:al2023"
+	legacyLambdaImage = "lambci/lambda:go1.x"
+
+	defaultLambdaFunctionName    = "function"
+	defaultLambdaHandler         = "handler"
+	defaultLambdaTimeout         = 3 * time.Second
+	defaultLambdaMemorySize      = 128
+	defaultLambdaFunctionVersion = "$LATEST"
+	defaultLambdaRegion          = "eu-west-1"
 )
 
 type LambdaDockerContainerConfig struct {
-	Executable  string
-	Hostname    string
-	Environment map[string]string
+	Executable string
+	// Package, if set instead of Executable, is the directory of the function's main package, which is built for the
+	// container when it starts
+	Package string
+	// DeploymentPackage, if set instead, is a zip file as deployed to AWS, which is extracted into /var/task, so it
+	// holds a bootstrap executable, or the Handler on the go1.x runtime
+	DeploymentPackage string
+	// Image, if set instead, is a Lambda container image, such as one built from public.ecr.aws/lambda/provided, which
+	// is run as it is, with its own handler unless Handler is set
+	Image string
+	// FunctionName is the name that the function is invoked by, and the default hostname of the container, which
+	// defaults to "function", and Handler is the handler it is configured with, which defaults to "handler"
+	FunctionName string
+	Handler      string
+	Hostname     string
+	Environment  map[string]string
+	// Runtime defaults to LambdaRuntimeProvidedAl2023
+	Runtime LambdaRuntime
+	// Timeout is how long an invocation may run before the runtime stops it, in whole seconds, which defaults to 3
+	// seconds as in AWS
+	Timeout time.Duration
+	// MemorySize is the memory in MB that the function reports having, which defaults to 128 as in AWS; if it is set,
+	// the container is limited to it as well
+	MemorySize int
+	// Architecture defaults to that of the host, so that the function isn't emulated, except on the go1.x runtime, which
+	// only supports LambdaArchitectureX86_64; an Executable must be built for it
+	Architecture LambdaArchitecture
+	// FunctionVersion defaults to $LATEST and Region to eu-west-1
+	FunctionVersion string
+	Region          string
+	// Layers are directories or zip files, as published to AWS, whose contents are copied into /opt in order, and
+	// Extensions are executables copied into /opt/extensions, which the runtime starts alongside the function; the
+	// go1.x runtime doesn't support extensions
+	Layers     []string
+	Extensions []string
+	// CoverageDir, if set, is the directory on the host that the function's coverage data is copied into when the
+	// container stops, to be merged with MergeLambdaCoverage.  A Package is built with coverage instrumentation of
+	// CoverPackages, or of its module if there are none; an Executable or DeploymentPackage must be built with -cover,
+	// and an Image isn't supported
+	CoverageDir   string
+	CoverPackages []string
+	// Watch, if set, reloads the function whenever its Executable, or the source of its Package, changes, without
+	// restarting the container or the rest of the network
+	Watch bool
+	// Instances is how many containers run the function, which defaults to 1.  As in AWS, each handles one invocation
+	// at a time, so this is how many invocations can run concurrently; those after the first are named after the
+	// Hostname, with -2, -3 and so on appended, and the Lambda's log is that of the first.  Each instance runs the
+	// Lambda's BeforeStart and BeforeStop hooks and appears in the network's events and StartupReport
+	Instances int
 }
 
 type LambdaDockerContainer struct {
 	DockerContainer
-	Config LambdaDockerContainerConfig
+	Config     LambdaDockerContainerConfig
+	executable string
+	reloadMu   sync.Mutex
+	watcher    *eventSourcePoller
+	instances  []*LambdaDockerContainer
+	idle       chan *LambdaDockerContainer
+	invoked    atomic.Bool
+	invoker    func(ctx context.Context, instance *LambdaDockerContainer, event any) (LambdaResponse, error)
+}
+
+func (c *LambdaDockerContainer) Image() string {
+	if c.Config.Image != "" {
+		return c.Config.Image
+	}
+	if c.Config.Runtime == LambdaRuntimeGo1x {
+		return legacyLambdaImage
+	}
+	return lambdaImage
 }
 
 func (c *LambdaDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	if c.Config.Hostname == "" {
 		c.Config.Hostname = "lambda"
+		if c.Config.FunctionName != "" {
+			c.Config.Hostname = c.Config.FunctionName
+		}
 	}
-	c.internalServicePort = 9001
+	if c.Config.Runtime == LambdaRuntimeGo1x && c.goarch() != "amd64" {
+		return fmt.Errorf("the %s Lambda runtime only supports the %s architecture", LambdaRuntimeGo1x, LambdaArchitectureX86_64)
+	}
+	if c.Config.Runtime == LambdaRuntimeGo1x && len(c.Config.Extensions) > 0 {
+		return fmt.Errorf("the %s Lambda runtime doesn't support extensions", LambdaRuntimeGo1x)
+	}
+	if err := c.checkDeployment(); err != nil {
+		return err
+	}
+	c.executable = c.Config.Executable
+	if c.Config.Package != "" {
+		executable, err := c.lambdaBuild().build(ctx)
+		if err != nil {
+			return err
+		}
+		c.executable = executable
+	}
+	deploymentPackage, err := c.addDeploymentPackage()
+	if err != nil {
+		return err
+	}
+	defer deploymentPackage.Close()
+	layers, err := c.addLayers()
+	if err != nil {
+		return err
+	}
+	defer layers.Close()
+	if err := c.startUsingRuntime(ctx, dockerNetwork); err != nil {
+		return err
+	}
+	if err := c.startInstances(ctx, dockerNetwork); err != nil {
+		return err
+	}
+	if c.Config.Watch {
+		return c.watch()
+	}
+	return nil
+}
+
+func (c *LambdaDockerContainer) startUsingRuntime(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	switch c.Config.Runtime {
+	case "", LambdaRuntimeProvidedAl2023:
+		return c.startUsingRuntimeInterfaceEmulator(ctx, dockerNetwork)
+	case LambdaRuntimeGo1x:
+		return c.startUsingLegacyImage(ctx, dockerNetwork)
+	}
+	return fmt.Errorf("unsupported Lambda runtime %s", c.Config.Runtime)
+}
+
+// startUsingRuntimeInterfaceEmulator runs the executable as the bootstrap of a custom runtime, which the image's
+// entrypoint runs under the Runtime Interface Emulator, listening for invocations on port 8080
+func (c *LambdaDockerContainer) startUsingRuntimeInterfaceEmulator(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	c.internalServicePort = 8080
 	req := testcontainers.ContainerRequest{
-		Image:        "lambci/lambda:go1.x",
-		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
-		Name:         c.Config.Hostname,
-		Hostname:     c.Config.Hostname,
-		Env:          c.setupEnvironment(),
-		Networks:     []string{dockerNetwork.Name},
+		Image:         c.Image(),
+		ExposedPorts:  []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
+		Name:          c.Config.Hostname,
+		Hostname:      c.Config.Hostname,
+		Env:           c.setupEnvironment(),
+		Cmd:           []string{c.handler()},
+		Networks:      []string{dockerNetwork.Name},
+		ImagePlatform: c.imagePlatform(),
 		HostConfigModifier: func(config *container.HostConfig) {
 			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
+			c.limitMemory(config)
 		},
 	}
-	var err error
-	c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
+	if c.Config.Image != "" && c.Config.Handler == "" {
+		req.Cmd = nil
+	}
+	return c.createAndStart(ctx, req, func(ctx context.Context) error {
+		if err := c.copyExecutable(ctx); err != nil {
+			return err
+		}
+		return c.copyExtensions(ctx)
 	})
-	if err != nil {
-		return fmt.Errorf("creating container: %w", err)
+}
+
+func (c *LambdaDockerContainer) startUsingLegacyImage(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	c.internalServicePort = 9001
+	req := testcontainers.ContainerRequest{
+		Image:         c.Image(),
+		ExposedPorts:  []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
+		Name:          c.Config.Hostname,
+		Hostname:      c.Config.Hostname,
+		Env:           c.setupEnvironment(),
+		Cmd:           []string{c.handler()},
+		Networks:      []string{dockerNetwork.Name},
+		ImagePlatform: c.imagePlatform(),
+		HostConfigModifier: func(config *container.HostConfig) {
+			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
+			c.limitMemory(config)
+		},
 	}
-	if err = c.testContainer.CopyFileToContainer(ctx, c.Config.Executable, "/var/task/handler", 365); err != nil {
-		return fmt.Errorf("copying binary to docker container: %v", err)
+	return c.createAndStart(ctx, req, c.copyExecutable)
+}
+
+// copyExecutable copies the Executable, or that built from the Package, into the container; a DeploymentPackage is
+// extracted, and an Image has its own
+func (c *LambdaDockerContainer) copyExecutable(ctx context.Context) error {
+	if c.Config.DeploymentPackage != "" || c.Config.Image != "" {
+		return nil
 	}
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+	if err := c.testContainer.CopyFileToContainer(ctx, c.executable, c.containerExecutable(), executableFileMode); err != nil {
+		return fmt.Errorf("copying binary to docker container: %w", err)
 	}
 	return nil
 }
 
+// FunctionName is the name of the function, by which it can be found in the network
+func (c *LambdaDockerContainer) FunctionName() string {
+	if c.Config.FunctionName == "" {
+		return defaultLambdaFunctionName
+	}
+	return c.Config.FunctionName
+}
+
+// containerExecutable is where the executable is installed in the container: as the bootstrap of the custom runtime,
+// or as the handler of the go1.x runtime.  The bootstrap of a DeploymentPackage is in /var/task, where the Runtime
+// Interface Emulator looks for it when there is none in /var/runtime
+func (c *LambdaDockerContainer) containerExecutable() string {
+	if c.Config.Runtime == LambdaRuntimeGo1x {
+		return path.Join(lambdaTaskDir, c.handler())
+	}
+	if c.Config.DeploymentPackage != "" {
+		return path.Join(lambdaTaskDir, "bootstrap")
+	}
+	return "/var/runtime/bootstrap"
+}
+
+func (c *LambdaDockerContainer) handler() string {
+	if c.Config.Handler == "" {
+		return defaultLambdaHandler
+	}
+	return c.Config.Handler
+}
+
+// FunctionArn is the unqualified ARN of the function in its Region
+func (c *LambdaDockerContainer) FunctionArn() string {
+	return fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", c.region(), eventAccountId, c.FunctionName())
+}
+
+func (c *LambdaDockerContainer) region() string {
+	return regionOrDefault(c.Config.Region)
+}
+
+func (c *LambdaDockerContainer) functionVersion() string {
+	if c.Config.FunctionVersion == "" {
+		return defaultLambdaFunctionVersion
+	}
+	return c.Config.FunctionVersion
+}
+
+// timeoutSeconds is the timeout rounded up to whole seconds, as AWS configures it
+func (c *LambdaDockerContainer) timeoutSeconds() int {
+	if c.Config.Timeout <= 0 {
+		return int(defaultLambdaTimeout.Seconds())
+	}
+	return int(math.Ceil(c.Config.Timeout.Seconds()))
+}
+
+func (c *LambdaDockerContainer) memorySize() int {
+	if c.Config.MemorySize == 0 {
+		return defaultLambdaMemorySize
+	}
+	return c.Config.MemorySize
+}
+
+// goarch is the GOARCH of the function's architecture
+func (c *LambdaDockerContainer) goarch() string {
+	switch c.Config.Architecture {
+	case LambdaArchitectureX86_64:
+		return "amd64"
+	case LambdaArchitectureArm64:
+		return "arm64"
+	}
+	if c.Config.Runtime == LambdaRuntimeGo1x {
+		return "amd64"
+	}
+	return runtime.GOARCH
+}
+
+// imagePlatform is the platform of the image to run, which is left to the host unless the architecture is set
+func (c *LambdaDockerContainer) imagePlatform() string {
+	if c.Config.Architecture == "" {
+		return ""
+	}
+	return "linux/" + c.goarch()
+}
+
+func (c *LambdaDockerContainer) limitMemory(config *container.HostConfig) {
+	if c.Config.MemorySize > 0 {
+		config.Memory = int64(c.Config.MemorySize) << 20
+	}
+}
+
+func (c *LambdaDockerContainer) lambdaBuild() lambdaBuild {
+	build := lambdaBuild{pkg: c.Config.Package, goarch: c.goarch(), cover: c.Config.CoverageDir != "", coverPackages: c.Config.CoverPackages}
+	if c.Config.Runtime != LambdaRuntimeGo1x {
+		// the provided runtimes don't need the RPC server that go1.x used to invoke the function
+		build.tags = append(build.tags, "lambda.norpc")
+	}
+	return build
+}
+
 func (c *LambdaDockerContainer) setupEnvironment() map[string]string {
 	env := map[string]string{
-		"ENVIRONMENT":             "dev",
-		"AWS_REGION":              "eu-west-1",
-		"DOCKER_LAMBDA_STAY_OPEN": "1",
-		"AWS_ACCESS_KEY_ID":       "x",
-		"AWS_SECRET_ACCESS_KEY":   "x",
+		"ENVIRONMENT":           "dev",
+		"AWS_REGION":            c.region(),
+		"AWS_DEFAULT_REGION":    c.region(),
+		"AWS_ACCESS_KEY_ID":     "x",
+		"AWS_SECRET_ACCESS_KEY": "x",
+		// the Runtime Interface Emulator reports the function by this name, rather than the name it is invoked by
+		"AWS_LAMBDA_FUNCTION_NAME": c.FunctionName(),
+		// both emulators stop invocations that run for longer than this, and report these in the function's context
+		"AWS_LAMBDA_FUNCTION_TIMEOUT":     strconv.Itoa(c.timeoutSeconds()),
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": strconv.Itoa(c.memorySize()),
+		"AWS_LAMBDA_FUNCTION_VERSION":     c.functionVersion(),
+		"AWS_LAMBDA_LOG_GROUP_NAME":       "/aws/lambda/" + c.FunctionName(),
+	}
+	if c.Config.Runtime == LambdaRuntimeGo1x {
+		env["DOCKER_LAMBDA_STAY_OPEN"] = "1"
+		// lambci/lambda restarts the function when the handler is replaced, which is how it is reloaded
+		env["DOCKER_LAMBDA_WATCH"] = "1"
+		// only lambci/lambda lets the ARN be set; the Runtime Interface Emulator makes up its own
+		env["AWS_LAMBDA_FUNCTION_INVOKED_ARN"] = c.FunctionArn()
+	}
+	if c.Config.CoverageDir != "" {
+		env["GOCOVERDIR"] = lambdaCoverageDataDir
 	}
 	for k, v := range c.Config.Environment {
 		env[k] = v
@@ -78,6 +376,12 @@ func (c *LambdaDockerContainer) Log() (*bytes.Buffer, error) {
 	return buf, nil
 }
 
+// InvocationUrl is the URL on the host of the Lambda Invoke API for the function, to which events can be posted
 func (c *LambdaDockerContainer) InvocationUrl() string {
-	return fmt.Sprintf("http://localhost:%d/2015-03-31/functions/myfunction/invocations", c.MappedPort())
+	functionName := c.FunctionName()
+	if c.Config.Runtime != LambdaRuntimeGo1x {
+		// the Runtime Interface Emulator only answers to the name "function", whatever the function is called
+		functionName = defaultLambdaFunctionName
+	}
+	return fmt.Sprintf("http://localhost:%d/2015-03-31/functions/%s/invocations", c.MappedPort(), functionName)
 }
@@ -0,0 +1,271 @@
+package testcontainernetwork
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lambdaBuildCacheMaxAge is how long a build in the cache can go unused before it is removed by the next build
+const lambdaBuildCacheMaxAge = 7 * 24 * time.Hour
+
+// lambdaGoEnvVars are the settings of the go command that can change what it builds, besides the target
+var lambdaGoEnvVars = []string{"GOVERSION", "GOFLAGS", "GOEXPERIMENT", "GOAMD64", "GOARM64", "GOWORK", "CGO_ENABLED", "GOTOOLCHAIN"}
+
+// lambdaGoEnvs caches the output of go env for each module, architecture and environment, so that watching a package
+// doesn't run the go command on every poll
+var lambdaGoEnvs sync.Map
+
+// lambdaBuild is a build of the main package in a directory into a Lambda bootstrap for the container
+type lambdaBuild struct {
+	pkg    string
+	goarch string
+	tags   []string
+	// cover builds the executable with coverage instrumentation of coverPackages, or of the main module if there are
+	// none
+	cover         bool
+	coverPackages []string
+}
+
+// build cross-compiles the package, unless a build of the same source is already in the cache, and returns the path
+// of the executable.  Compiler errors are returned with the compiler's output
+func (b lambdaBuild) build(ctx context.Context) (string, error) {
+	hash, err := b.sourceHash()
+	if err != nil {
+		return "", fmt.Errorf("hashing Lambda package %s: %w", b.pkg, err)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	cacheRoot := filepath.Join(cacheDir, "testcontainernetwork", "lambda")
+	executable := filepath.Join(cacheRoot, hash, "bootstrap")
+	if _, err := os.Stat(executable); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(filepath.Dir(executable), now, now)
+		return executable, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(executable), 0o755); err != nil {
+		return "", fmt.Errorf("creating Lambda build cache: %w", err)
+	}
+	output, err := os.CreateTemp(filepath.Dir(executable), "bootstrap-*")
+	if err != nil {
+		return "", fmt.Errorf("creating Lambda build output: %w", err)
+	}
+	output.Close()
+	defer os.Remove(output.Name())
+
+	var stderr bytes.Buffer
+	args := []string{"build", "-tags", strings.Join(b.tags, ",")}
+	if b.cover {
+		args = append(args, "-cover")
+		if len(b.coverPackages) > 0 {
+			args = append(args, "-coverpkg="+strings.Join(b.coverPackages, ","))
+		}
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, "-o", output.Name(), ".")...)
+	cmd.Dir = b.pkg
+	cmd.Env = b.env()
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("building Lambda package %s: %w\n%s", b.pkg, err, strings.TrimSpace(stderr.String()))
+	}
+	if err := os.Rename(output.Name(), executable); err != nil {
+		return "", fmt.Errorf("caching Lambda executable: %w", err)
+	}
+	pruneLambdaBuildCache(cacheRoot, lambdaBuildCacheMaxAge)
+	return executable, nil
+}
+
+// env is the environment of the go command, which cross-compiles a static executable
+func (b lambdaBuild) env() []string {
+	return append(os.Environ(), "GOOS=linux", "GOARCH="+b.goarch, "CGO_ENABLED=0")
+}
+
+// pruneLambdaBuildCache removes the builds in the cache that haven't been used for maxAge, as a build is touched each
+// time it is used
+func pruneLambdaBuildCache(cacheRoot string, maxAge time.Duration) {
+	entries, err := os.ReadDir(cacheRoot)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && entry.IsDir() && time.Since(info.ModTime()) > maxAge {
+			_ = os.RemoveAll(filepath.Join(cacheRoot, entry.Name()))
+		}
+	}
+}
+
+// sourceHash hashes the files that go list says the package and the packages it imports are built from, other than
+// those in the module cache, which go.sum identifies, along with the go.mod and go.sum files of their modules, the
+// go.work file of the workspace, if any, and the settings of the build and of the go command, so that any change that
+// could change the executable gives a different hash
+func (b lambdaBuild) sourceHash() (string, error) {
+	moduleRoot, err := moduleRootOf(b.pkg)
+	if err != nil {
+		return "", err
+	}
+	goEnv, err := b.goEnv(moduleRoot)
+	if err != nil {
+		return "", err
+	}
+	packages, err := b.dependencies()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s %v %t %v\n%s", b.goarch, b.pkg, b.tags, b.cover, b.coverPackages, goEnv)
+	var files []string
+	if goWork := strings.TrimSpace(strings.Split(goEnv, "\n")[slices.Index(lambdaGoEnvVars, "GOWORK")]); goWork != "" && goWork != "off" {
+		files = append(files, goWork, goWork+".sum")
+	}
+	for _, pkg := range packages {
+		if !pkg.local() {
+			continue
+		}
+		if pkg.Module != nil && pkg.Module.GoMod != "" {
+			files = append(files, pkg.Module.GoMod, filepath.Join(filepath.Dir(pkg.Module.GoMod), "go.sum"))
+		}
+		for _, name := range pkg.files() {
+			files = append(files, filepath.Join(pkg.Dir, name))
+		}
+	}
+	hashed := map[string]bool{}
+	for _, file := range files {
+		if hashed[file] {
+			continue
+		}
+		hashed[file] = true
+		if err := hashFile(hash, file); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// goListPackage is the part of the output of go list -json that says which files a package is built from
+type goListPackage struct {
+	Dir      string
+	Standard bool
+	Module   *struct {
+		GoMod   string
+		Main    bool
+		Replace *struct {
+			Version string
+		}
+	}
+	GoFiles, CgoFiles, CFiles, CXXFiles, MFiles, HFiles, FFiles, SFiles, SwigFiles, SwigCXXFiles, SysoFiles []string
+	EmbedFiles, IgnoredGoFiles, IgnoredOtherFiles                                                           []string
+}
+
+// goListPackageFields are the fields of goListPackage, which go list outputs rather than all of them
+const goListPackageFields = "Dir,Standard,Module,GoFiles,CgoFiles,CFiles,CXXFiles,MFiles,HFiles,FFiles,SFiles,SwigFiles," +
+	"SwigCXXFiles,SysoFiles,EmbedFiles,IgnoredGoFiles,IgnoredOtherFiles"
+
+// local reports whether the package is in the main module, the workspace or a module replaced with a local directory,
+// rather than in the module cache
+func (p goListPackage) local() bool {
+	if p.Standard {
+		return false
+	}
+	return p.Module == nil || p.Module.Main || (p.Module.Replace != nil && p.Module.Replace.Version == "")
+}
+
+// files are the names of the files in the package's directory that it is built from, along with those that are left
+// out by build constraints, since editing a constraint can bring them in
+func (p goListPackage) files() []string {
+	return slices.Concat(p.GoFiles, p.CgoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.FFiles, p.SFiles, p.SwigFiles,
+		p.SwigCXXFiles, p.SysoFiles, p.EmbedFiles, p.IgnoredGoFiles, p.IgnoredOtherFiles)
+}
+
+// dependencies lists the package and all the packages that it imports, as they are built for the container.  Packages
+// with errors are listed too, so that a package that doesn't compile still has a hash, and fails when it is built
+func (b lambdaBuild) dependencies() ([]goListPackage, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-deps", "-json="+goListPackageFields, "-tags", strings.Join(b.tags, ","), ".")
+	cmd.Dir = b.pkg
+	cmd.Env = b.env()
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing dependencies: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	var packages []goListPackage
+	for decoder := json.NewDecoder(bytes.NewReader(output)); decoder.More(); {
+		var pkg goListPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("reading dependencies: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// hashFile hashes the path and content of a file, if it exists
+func hashFile(hash io.Writer, path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintf(hash, "%s\n", path)
+	_, err = io.Copy(hash, f)
+	return err
+}
+
+// goEnv returns the settings of the go command that the package is built with, one per line in the order of
+// lambdaGoEnvVars
+func (b lambdaBuild) goEnv(moduleRoot string) (string, error) {
+	key := moduleRoot + " " + b.goarch
+	for _, name := range lambdaGoEnvVars {
+		key += " " + os.Getenv(name)
+	}
+	if goEnv, ok := lambdaGoEnvs.Load(key); ok {
+		return goEnv.(string), nil
+	}
+	cmd := exec.Command("go", append([]string{"env"}, lambdaGoEnvVars...)...)
+	cmd.Dir = moduleRoot
+	cmd.Env = b.env()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading go env: %w", err)
+	}
+	lambdaGoEnvs.Store(key, string(output))
+	return string(output), nil
+}
+
+// moduleRootOf returns the directory containing the go.mod of the module that the directory dir is in
+func moduleRootOf(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(current) == current {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lambdaPackage(t *testing.T, source string) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/function\n\ngo 1.21\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644))
+	return dir
+}
+
+// withEmptyLambdaBuildCache moves the user cache to a temporary directory, keeping Go's own build cache where it was
+func withEmptyLambdaBuildCache(t *testing.T) {
+	goCache, err := exec.Command("go", "env", "GOCACHE").Output()
+	assert.NoError(t, err)
+	t.Setenv("GOCACHE", strings.TrimSpace(string(goCache)))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+}
+
+func TestLambdaBuild_BuildsAndCachesExecutable(t *testing.T) {
+	withEmptyLambdaBuildCache(t)
+	build := lambdaBuild{pkg: lambdaPackage(t, "package main\n\nfunc main() {}\n"), goarch: runtime.GOARCH}
+
+	executable, err := build.build(context.Background())
+	assert.NoError(t, err)
+	assert.FileExists(t, executable)
+	assert.Equal(t, "bootstrap", filepath.Base(executable))
+
+	cached, err := build.build(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, executable, cached)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(build.pkg, "main.go"), []byte("package main\n\nfunc main() { println() }\n"), 0o644))
+	rebuilt, err := build.build(context.Background())
+	assert.NoError(t, err)
+	assert.NotEqual(t, executable, rebuilt)
+}
+
+func TestLambdaBuild_ReturnsCompileErrors(t *testing.T) {
+	withEmptyLambdaBuildCache(t)
+	build := lambdaBuild{pkg: lambdaPackage(t, "package main\n\nfunc main() { undefined() }\n"), goarch: runtime.GOARCH}
+
+	_, err := build.build(context.Background())
+
+	assert.ErrorContains(t, err, "undefined: undefined")
+}
+
+func TestLambdaDockerContainer_FailsToStartWhenPackageDoesNotCompile(t *testing.T) {
+	withEmptyLambdaBuildCache(t)
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Package: lambdaPackage(t, "package main\n\nfunc main() { undefined() }\n"),
+	}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "undefined: undefined")
+	assert.NotContains(t, callsAsStrings(provider.Calls()), "create container lambda public.ecr.aws/lambda/provided:al2023")
+}
+
+func TestLambdaBuild_SourceHashCoversGoFlagsAndReplacedModules(t *testing.T) {
+	lib := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(lib, "go.mod"), []byte("module example.com/lib\n\ngo 1.21\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n\nconst Name = \"a\"\n"), 0o644))
+	build := lambdaBuild{pkg: lambdaPackage(t, "package main\n\nimport \"example.com/lib\"\n\nfunc main() { println(lib.Name) }\n"), goarch: runtime.GOARCH}
+	assert.NoError(t, os.WriteFile(filepath.Join(build.pkg, "go.mod"),
+		[]byte("module example.com/function\n\ngo 1.21\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => "+lib+"\n"), 0o644))
+
+	original, err := build.sourceHash()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n\nconst Name = \"b\"\n"), 0o644))
+	libChanged, err := build.sourceHash()
+	assert.NoError(t, err)
+	t.Setenv("GOFLAGS", "-trimpath")
+	goFlagsChanged, err := build.sourceHash()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, original, libChanged)
+	assert.NotEqual(t, libChanged, goFlagsChanged)
+}
+
+func TestLambdaBuild_SourceHashCoversEmbeddedFilesButNotFilesOutsideThePackage(t *testing.T) {
+	build := lambdaBuild{pkg: lambdaPackage(t, "package main\n\nimport _ \"embed\"\n\n//go:embed rates.json\nvar rates string\n\nfunc main() { println(rates) }\n"), goarch: runtime.GOARCH}
+	assert.NoError(t, os.WriteFile(filepath.Join(build.pkg, "rates.json"), []byte("{}"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(build.pkg, "testdata"), 0o755))
+
+	original, err := build.sourceHash()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(build.pkg, "testdata", "order.json"), []byte("{}"), 0o644))
+	testdataChanged, err := build.sourceHash()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(build.pkg, "rates.json"), []byte(`{"GBP": 1}`), 0o644))
+	embeddedFileChanged, err := build.sourceHash()
+	assert.NoError(t, err)
+
+	assert.Equal(t, original, testdataChanged)
+	assert.NotEqual(t, original, embeddedFileChanged)
+}
+
+func TestPruneLambdaBuildCache_RemovesBuildsUnusedForMaxAge(t *testing.T) {
+	cacheRoot := t.TempDir()
+	for _, build := range []string{"unused", "used"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(cacheRoot, build), 0o755))
+	}
+	longAgo := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(cacheRoot, "unused"), longAgo, longAgo))
+
+	pruneLambdaBuildCache(cacheRoot, 24*time.Hour)
+
+	assert.NoDirExists(t, filepath.Join(cacheRoot, "unused"))
+	assert.DirExists(t, filepath.Join(cacheRoot, "used"))
+}
@@ -0,0 +1,203 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/testcontainers/testcontainers-go"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LambdaInvocationResult is the outcome of one of the invocations made by InvokeConcurrently: Instance is the hostname
+// of the container that handled it, and Duration how long it took from the invocation being sent to an instance to
+// the response, not counting any wait for an instance to be free
+type LambdaInvocationResult struct {
+	Instance  string
+	ColdStart bool
+	Duration  time.Duration
+	Response  LambdaResponse
+	Err       error
+}
+
+// LambdaInvocationReport is the results of InvokeConcurrently, in the order of the events
+type LambdaInvocationReport []LambdaInvocationResult
+
+// LambdaLatencies is the distribution of the durations of a set of invocations, whose percentiles are nearest-rank
+type LambdaLatencies struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// startInstances starts the containers beyond the first that run the function, running the Lambda's BeforeStart hooks
+// and reporting each to the network as it does the first, and makes them all available to take invocations.  Those
+// that it attempts to start are kept in instances, so that stopping the Lambda stops them should any fail
+func (c *LambdaDockerContainer) startInstances(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	c.idle = make(chan *LambdaDockerContainer, c.instanceCount())
+	c.idle <- c
+	for i := 2; i <= c.instanceCount(); i++ {
+		instance := &LambdaDockerContainer{Config: c.Config}
+		instance.Config.Hostname = fmt.Sprintf("%s-%d", c.Config.Hostname, i)
+		instance.Config.Instances = 1
+		instance.Config.Watch = false
+		instance.hooks = c.hooks
+		instance.memberships = c.memberships
+		instance.copies = c.copies
+		instance.joinNetwork(c.network)
+		if err := runHooks(ctx, instance.hooks.BeforeStart); err != nil {
+			return fmt.Errorf("running before start hooks of instance %d of Lambda %s: %w", i, c.FunctionName(), err)
+		}
+		started := time.Now()
+		c.instances = append(c.instances, instance)
+		if err := instance.StartUsing(ctx, dockerNetwork); err != nil {
+			return fmt.Errorf("starting instance %d of Lambda %s: %w", i, c.FunctionName(), err)
+		}
+		if c.network != nil {
+			c.network.containerStarted(instance, started)
+		}
+		c.idle <- instance
+	}
+	return nil
+}
+
+func (c *LambdaDockerContainer) instanceCount() int {
+	return max(c.Config.Instances, 1)
+}
+
+// stopInstances stops the containers beyond the first that run the function, running the Lambda's BeforeStop hooks
+// before each, as the network does before the first
+func (c *LambdaDockerContainer) stopInstances(ctx context.Context) error {
+	var errs []error
+	for _, instance := range c.instances {
+		if err := runHooks(ctx, instance.hooks.BeforeStop); err != nil {
+			errs = append(errs, fmt.Errorf("running before stop hooks of Lambda instance %s: %w", instance.Config.Hostname, err))
+		}
+		created := instance.testContainer != nil
+		stopping := time.Now()
+		if err := instance.Stop(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		c.emit(Event{Type: EventContainerStopped, Container: instance.containerName(), Image: instance.Image(), Duration: time.Since(stopping)})
+	}
+	c.instances = nil
+	return errors.Join(errs...)
+}
+
+// invoke invokes the function on an idle instance, waiting for one if they are all busy, as each handles one
+// invocation at a time
+func (c *LambdaDockerContainer) invoke(ctx context.Context, event any) LambdaInvocationResult {
+	instance := c
+	if c.idle != nil {
+		select {
+		case instance = <-c.idle:
+			defer func() { c.idle <- instance }()
+		case <-ctx.Done():
+			return LambdaInvocationResult{Err: fmt.Errorf("invoking Lambda: %w", ctx.Err())}
+		}
+	}
+	coldStart := !instance.invoked.Swap(true)
+	started := time.Now()
+	var response LambdaResponse
+	var err error
+	if c.invoker != nil {
+		response, err = c.invoker(ctx, instance, event)
+	} else {
+		response, err = invokeLambda(ctx, instance.InvocationUrl(), event)
+	}
+	return LambdaInvocationResult{Instance: instance.Config.Hostname, ColdStart: coldStart, Duration: time.Since(started), Response: response, Err: err}
+}
+
+// InvokeConcurrently invokes the Lambda with all the events at once, spread across its Instances, and reports how long
+// each invocation took and whether it was a cold start, being the first on its instance since it started or was
+// reloaded.  Events beyond the number of Instances wait for an instance to be free, as invocations beyond the
+// reserved concurrency of a function would be throttled in AWS
+func (c *LambdaDockerContainer) InvokeConcurrently(ctx context.Context, events ...any) LambdaInvocationReport {
+	report := make(LambdaInvocationReport, len(events))
+	var wg sync.WaitGroup
+	for i, event := range events {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report[i] = c.invoke(ctx, event)
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// ColdStarts returns the latencies of the invocations that were cold starts
+func (r LambdaInvocationReport) ColdStarts() LambdaLatencies {
+	return r.latencies(func(result LambdaInvocationResult) bool { return result.ColdStart })
+}
+
+// WarmStarts returns the latencies of the invocations that found their instance already running the function
+func (r LambdaInvocationReport) WarmStarts() LambdaLatencies {
+	return r.latencies(func(result LambdaInvocationResult) bool { return !result.ColdStart })
+}
+
+// Errors returns the errors of the invocations that failed, including function errors
+func (r LambdaInvocationReport) Errors() []error {
+	var errs []error
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errs
+}
+
+func (r LambdaInvocationReport) latencies(include func(result LambdaInvocationResult) bool) LambdaLatencies {
+	var durations []time.Duration
+	var total time.Duration
+	for _, result := range r {
+		if include(result) {
+			durations = append(durations, result.Duration)
+			total += result.Duration
+		}
+	}
+	if len(durations) == 0 {
+		return LambdaLatencies{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	percentile := func(p int) time.Duration {
+		return durations[max((p*len(durations)+99)/100, 1)-1]
+	}
+	return LambdaLatencies{
+		Count: len(durations),
+		Min:   durations[0],
+		Mean:  total / time.Duration(len(durations)),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   durations[len(durations)-1],
+	}
+}
+
+func (r LambdaInvocationReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-6s %6s %10s %10s %10s %10s %10s %10s\n", "START", "COUNT", "MIN", "MEAN", "P50", "P90", "P99", "MAX")
+	for _, row := range []struct {
+		start     string
+		latencies LambdaLatencies
+	}{{"cold", r.ColdStarts()}, {"warm", r.WarmStarts()}} {
+		l := row.latencies
+		fmt.Fprintf(&sb, "%-6s %6d %10s %10s %10s %10s %10s %10s\n", row.start, l.Count, l.Min.Round(time.Millisecond),
+			l.Mean.Round(time.Millisecond), l.P50.Round(time.Millisecond), l.P90.Round(time.Millisecond),
+			l.P99.Round(time.Millisecond), l.Max.Round(time.Millisecond))
+	}
+	if errs := r.Errors(); len(errs) > 0 {
+		fmt.Fprintf(&sb, "%d of %d invocations failed\n", len(errs), len(r))
+	}
+	return sb.String()
+}
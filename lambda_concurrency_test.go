@@ -0,0 +1,177 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLambdaDockerContainer_StartsAndStopsInstances(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", Instances: 3}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+
+	calls := callsAsStrings(provider.Calls())
+	for _, name := range []string{"orders", "orders-2", "orders-3"} {
+		req, ok := provider.Request(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, name, req.Hostname)
+		assert.Equal(t, "orders", req.Env["AWS_LAMBDA_FUNCTION_NAME"])
+		assert.Contains(t, calls, "copy to container "+name+" main -> /var/runtime/bootstrap (755)")
+		assert.Contains(t, calls, "stop container "+name)
+	}
+}
+
+func TestLambdaDockerContainer_ReportsInstancesToTheNetworkAndRunsTheirHooks(t *testing.T) {
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", Instances: 2}}
+	var beforeStart, beforeStop int
+	lambdaContainer.BeforeStart(func(context.Context) error {
+		beforeStart++
+		return nil
+	})
+	lambdaContainer.BeforeStop(func(context.Context) error {
+		beforeStop++
+		return nil
+	})
+	containers := map[EventType][]string{}
+	network := fakeNetwork(&FakeContainerProvider{}, lambdaContainer).WithEventListener(EventListenerFunc(func(event Event) {
+		containers[event.Type] = append(containers[event.Type], event.Container)
+	}))
+
+	assert.NoError(t, network.StartWithDelay(0))
+	report := network.StartupReport()
+	assert.NoError(t, network.Stop())
+
+	assert.ElementsMatch(t, []string{"orders", "orders-2"}, []string{report[0].Container, report[1].Container})
+	assert.Equal(t, 2, beforeStart)
+	assert.Equal(t, 2, beforeStop)
+	for _, eventType := range []EventType{EventContainerStarted, EventContainerReady, EventContainerStopped} {
+		assert.ElementsMatch(t, []string{"orders", "orders-2"}, containers[eventType], eventType)
+	}
+}
+
+func TestLambdaDockerContainer_StartsInstancesOnItsNetworksWithItsCopies(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", Instances: 2}}
+	lambdaContainer.OnNetwork("private")
+	lambdaContainer.CopyDirectory(fstest.MapFS{"rates.json": {Data: []byte("{}")}}, "/var/task/config")
+	network := fakeNetwork(provider, lambdaContainer).WithSubNetwork("private")
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	for _, name := range []string{"orders", "orders-2"} {
+		req, _ := provider.Request(name)
+		assert.Equal(t, []string{"fake-network-2"}, req.Networks, name)
+		assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container "+name+" 2 bytes -> /var/task/config/rates.json (644)")
+	}
+}
+
+func TestLambdaDockerContainer_StopsStartedInstancesWhenAnotherFailsToStart(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	provider.FailOn(FakeStartContainer, "orders-3", errors.New("no space left on device"))
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", Instances: 3}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "starting instance 3 of Lambda orders: starting container: no space left on device")
+	calls := callsAsStrings(provider.Calls())
+	for _, name := range []string{"orders", "orders-2", "orders-3"} {
+		assert.Contains(t, calls, "stop container "+name)
+	}
+	assert.Equal(t, "remove network fake-network-1", calls[len(calls)-1])
+}
+
+func TestLambdaDockerContainer_InvokesInstancesConcurrentlyOneInvocationEach(t *testing.T) {
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", Instances: 3}}
+	network := fakeNetwork(&FakeContainerProvider{}, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+	var mu sync.Mutex
+	running := map[string]bool{}
+	concurrent, maxConcurrent := 0, 0
+	lambdaContainer.invoker = func(_ context.Context, instance *LambdaDockerContainer, event any) (LambdaResponse, error) {
+		mu.Lock()
+		assert.False(t, running[instance.Config.Hostname], "instance %s invoked while busy", instance.Config.Hostname)
+		running[instance.Config.Hostname] = true
+		concurrent++
+		maxConcurrent = max(maxConcurrent, concurrent)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running[instance.Config.Hostname] = false
+		concurrent--
+		mu.Unlock()
+		if event == "fail" {
+			return LambdaResponse{}, errors.New("function failed")
+		}
+		return LambdaResponse{StatusCode: 200, Payload: []byte(`"ok"`)}, nil
+	}
+
+	report := lambdaContainer.InvokeConcurrently(context.Background(), "a", "b", "c", "d", "e", "fail")
+
+	assert.Equal(t, 3, maxConcurrent)
+	assert.Len(t, report, 6)
+	assert.Equal(t, 3, report.ColdStarts().Count)
+	assert.Equal(t, 3, report.WarmStarts().Count)
+	assert.GreaterOrEqual(t, report.ColdStarts().Min, 20*time.Millisecond)
+	assert.EqualError(t, errors.Join(report.Errors()...), "function failed")
+	assert.Equal(t, `"ok"`, string(report[0].Response.Payload))
+	instances := map[string]bool{}
+	for _, result := range report {
+		instances[result.Instance] = true
+	}
+	assert.Equal(t, map[string]bool{"lambda": true, "lambda-2": true, "lambda-3": true}, instances)
+}
+
+func TestLambdaDockerContainer_ReloadMakesInstancesColdAgain(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", Instances: 2}}
+	network := fakeNetwork(provider, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+	lambdaContainer.invoker = func(context.Context, *LambdaDockerContainer, any) (LambdaResponse, error) {
+		time.Sleep(10 * time.Millisecond)
+		return LambdaResponse{StatusCode: 200}, nil
+	}
+	lambdaContainer.InvokeConcurrently(context.Background(), "a", "b")
+
+	assert.NoError(t, lambdaContainer.Reload(context.Background()))
+	report := lambdaContainer.InvokeConcurrently(context.Background(), "a", "b")
+
+	assert.Equal(t, 2, report.ColdStarts().Count)
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container lambda-2 main -> /var/runtime/bootstrap.reload (755)")
+}
+
+func TestLambdaDockerContainer_ReloadCarriesOnPastFailingInstancesAndNamesThem(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", Instances: 3}}
+	network := fakeNetwork(provider, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+	provider.FailOn(FakeCopyToContainer, "orders-2", errors.New("disk full"))
+
+	err := lambdaContainer.Reload(context.Background())
+
+	assert.EqualError(t, err, "reloading Lambda orders: orders-2 still running the old executable: "+
+		"orders-2: copying binary to docker container: disk full")
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container orders-3 main -> /var/runtime/bootstrap.reload (755)")
+}
+
+func TestLambdaInvocationReport_SummarisesLatencies(t *testing.T) {
+	report := LambdaInvocationReport{{ColdStart: true, Duration: 900 * time.Millisecond}}
+	for i := 1; i <= 10; i++ {
+		report = append(report, LambdaInvocationResult{Duration: time.Duration(i) * 10 * time.Millisecond})
+	}
+
+	assert.Equal(t, LambdaLatencies{Count: 1, Min: 900 * time.Millisecond, Mean: 900 * time.Millisecond, P50: 900 * time.Millisecond,
+		P90: 900 * time.Millisecond, P99: 900 * time.Millisecond, Max: 900 * time.Millisecond}, report.ColdStarts())
+	assert.Equal(t, LambdaLatencies{Count: 10, Min: 10 * time.Millisecond, Mean: 55 * time.Millisecond, P50: 50 * time.Millisecond,
+		P90: 90 * time.Millisecond, P99: 100 * time.Millisecond, Max: 100 * time.Millisecond}, report.WarmStarts())
+	assert.Equal(t, LambdaLatencies{}, LambdaInvocationReport{}.ColdStarts())
+	assert.Equal(t, "START   COUNT        MIN       MEAN        P50        P90        P99        MAX\n"+
+		"cold        1      900ms      900ms      900ms      900ms      900ms      900ms\n"+
+		"warm       10       10ms       55ms       50ms       90ms      100ms      100ms\n", report.String())
+}
@@ -0,0 +1,158 @@
+package testcontainernetwork
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// lambdaCoverageDataDir is where a function built with -cover writes its coverage data in the container, which is
+// the only directory that Lambda functions can write to
+const lambdaCoverageDataDir = "/tmp"
+
+// stopLambdaFunctionScript sends SIGTERM to the processes running the executable given as its argument, and waits up
+// to five seconds for each to exit
+const stopLambdaFunctionScript = `for process in /proc/[0-9]*; do
+	if [ "$(readlink "$process/exe")" = "$1" ]; then
+		kill -TERM "${process#/proc/}"
+		i=0
+		while [ "$(readlink "$process/exe")" = "$1" ] && [ $i -lt 50 ]; do
+			sleep 0.1
+			i=$((i + 1))
+		done
+	fi
+done
+true`
+
+// Stop stops watching the function and stops its Instances, copying their coverage data into CoverageDir, if it is
+// set, before stopping their containers
+func (c *LambdaDockerContainer) Stop(ctx context.Context) error {
+	c.stopWatching()
+	instancesErr := c.stopInstances(ctx)
+	if c.Config.CoverageDir != "" && c.testContainer != nil {
+		if err := c.collectCoverage(ctx); err != nil {
+			return errors.Join(instancesErr, err, c.DockerContainer.Stop(ctx))
+		}
+	}
+	return errors.Join(instancesErr, c.DockerContainer.Stop(ctx))
+}
+
+// collectCoverage stops the function, which writes its coverage counters as it exits, and copies the coverage data
+// into CoverageDir, emitting EventLambdaCoverageMissing if the function wrote none
+func (c *LambdaDockerContainer) collectCoverage(ctx context.Context) error {
+	if err := c.exec(ctx, "sh", "-c", stopLambdaFunctionScript, "sh", c.containerExecutable()); err != nil {
+		return fmt.Errorf("stopping Lambda function to collect coverage: %w", err)
+	}
+	listing, err := c.execOutput(ctx, "ls", "-1", lambdaCoverageDataDir)
+	if err != nil {
+		return fmt.Errorf("listing Lambda coverage data: %w", err)
+	}
+	if err := os.MkdirAll(c.Config.CoverageDir, 0o755); err != nil {
+		return fmt.Errorf("creating coverage directory: %w", err)
+	}
+
+	var metaFiles, counterFiles int
+	for _, name := range strings.Fields(listing) {
+		switch {
+		case strings.HasPrefix(name, "covmeta."):
+			metaFiles++
+		case strings.HasPrefix(name, "covcounters."):
+			counterFiles++
+		default:
+			continue
+		}
+		if err := c.copyFileFromContainer(ctx, path.Join(lambdaCoverageDataDir, name), filepath.Join(c.Config.CoverageDir, name)); err != nil {
+			return err
+		}
+	}
+	if metaFiles > 0 && counterFiles == 0 {
+		// missing coverage mustn't stop the network from being torn down, so it is only a warning
+		c.emit(Event{Type: EventLambdaCoverageMissing, Container: c.name, Image: c.Image(),
+			Err: fmt.Errorf("no coverage counters from Lambda %s: the function writes them as it exits, so it must exit when it receives SIGTERM", c.FunctionName())})
+	}
+	return nil
+}
+
+func (c *LambdaDockerContainer) copyFileFromContainer(ctx context.Context, containerFile string, hostFile string) error {
+	reader, err := c.testContainer.CopyFileFromContainer(ctx, containerFile)
+	if err != nil {
+		return fmt.Errorf("copying %s from docker container: %w", containerFile, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("copying %s from docker container: %w", containerFile, err)
+	}
+	if err := os.WriteFile(hostFile, content, configFileMode); err != nil {
+		return fmt.Errorf("writing %s: %w", hostFile, err)
+	}
+	return nil
+}
+
+// MergeLambdaCoverage merges the coverage data that Lambdas have copied into coverageDir into the coverage profile that
+// the test binary writes for -coverprofile, so that the profile covers the functions as well as the tests.  Call it
+// from TestMain after m.Run, which writes the profile; it does nothing if coverage isn't being profiled
+func MergeLambdaCoverage(coverageDir string) error {
+	coverProfile := flag.Lookup("test.coverprofile")
+	if coverProfile == nil || coverProfile.Value.String() == "" {
+		return nil
+	}
+	profile := coverProfile.Value.String()
+	if outputDir := flag.Lookup("test.outputdir"); outputDir != nil && !filepath.IsAbs(profile) {
+		profile = filepath.Join(outputDir.Value.String(), profile)
+	}
+	return mergeCoverage(coverageDir, profile)
+}
+
+// mergeCoverage converts the coverage data in coverageDir into a text profile and appends its blocks to profile
+func mergeCoverage(coverageDir string, profile string) error {
+	if _, err := os.Stat(coverageDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	textProfile, err := os.CreateTemp("", "lambda-coverage-*.out")
+	if err != nil {
+		return fmt.Errorf("creating Lambda coverage profile: %w", err)
+	}
+	textProfile.Close()
+	defer os.Remove(textProfile.Name())
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+coverageDir, "-o="+textProfile.Name())
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("converting Lambda coverage data: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	content, err := os.ReadFile(textProfile.Name())
+	if err != nil {
+		return fmt.Errorf("reading Lambda coverage profile: %w", err)
+	}
+	_, blocks, _ := bytes.Cut(content, []byte("\n"))
+	if len(bytes.TrimSpace(blocks)) == 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(profile); errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(profile, content, configFileMode); err != nil {
+			return fmt.Errorf("writing coverage profile: %w", err)
+		}
+		return nil
+	}
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("opening coverage profile: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(blocks); err != nil {
+		return fmt.Errorf("writing coverage profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLambdaDockerContainer_CopiesCoverageDataWhenStopped(t *testing.T) {
+	coverageDir := filepath.Join(t.TempDir(), "coverage")
+	provider := &FakeContainerProvider{
+		ExecOutputs: map[string]map[string]string{"lambda": {"ls -1 /tmp": "covcounters.abc.1.2\ncovmeta.abc\nscratch.txt\n"}},
+		Files:       map[string]map[string]string{"lambda": {"/tmp/covmeta.abc": "meta", "/tmp/covcounters.abc.1.2": "counters"}},
+	}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", CoverageDir: coverageDir}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, "/tmp", req.Env["GOCOVERDIR"])
+	meta, _ := os.ReadFile(filepath.Join(coverageDir, "covmeta.abc"))
+	assert.Equal(t, "meta", string(meta))
+	counters, _ := os.ReadFile(filepath.Join(coverageDir, "covcounters.abc.1.2"))
+	assert.Equal(t, "counters", string(counters))
+	assert.NoFileExists(t, filepath.Join(coverageDir, "scratch.txt"))
+	assert.Contains(t, callsAsStrings(provider.Calls()), "stop container lambda")
+}
+
+func TestLambdaDockerContainer_WarnsOfFunctionThatWroteNoCoverageCounters(t *testing.T) {
+	provider := &FakeContainerProvider{
+		ExecOutputs: map[string]map[string]string{"orders": {"ls -1 /tmp": "covmeta.abc\n"}},
+		Files:       map[string]map[string]string{"orders": {"/tmp/covmeta.abc": "meta"}},
+	}
+	var warnings []Event
+	network := fakeNetwork(provider,
+		&LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders", CoverageDir: t.TempDir()}},
+		&DynamoDbDockerContainer{Config: DynamoDbDockerContainerConfig{Hostname: "dynamodb", Port: 8000}},
+	).WithEventListener(EventListenerFunc(func(event Event) {
+		if event.Type == EventLambdaCoverageMissing {
+			warnings = append(warnings, event)
+		}
+	}))
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.NoError(t, network.Stop())
+
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "orders", warnings[0].Container)
+	assert.EqualError(t, warnings[0].Err, "no coverage counters from Lambda orders: the function writes them as it exits, so it must exit when it receives SIGTERM")
+	calls := callsAsStrings(provider.Calls())
+	assert.Contains(t, calls, "stop container orders")
+	assert.Contains(t, calls, "stop container dynamodb")
+	assert.Contains(t, calls, "remove network fake-network-1")
+}
+
+func TestMergeCoverage_AppendsCoverageOfCoverBuildToProfile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the Lambda executable only runs on Linux")
+	}
+	withEmptyLambdaBuildCache(t)
+	build := lambdaBuild{pkg: lambdaPackage(t, "package main\n\nfunc main() {\n\tprintln(\"covered\")\n}\n"), goarch: runtime.GOARCH, cover: true}
+	executable, err := build.build(context.Background())
+	assert.NoError(t, err)
+
+	coverageDir := t.TempDir()
+	cmd := exec.Command(executable)
+	cmd.Env = append(os.Environ(), "GOCOVERDIR="+coverageDir)
+	assert.NoError(t, cmd.Run())
+	profile := filepath.Join(t.TempDir(), "coverage.out")
+	assert.NoError(t, os.WriteFile(profile, []byte("mode: set\nexample.com/tests/main.go:3.13,5.2 1 1\n"), 0o644))
+
+	assert.NoError(t, mergeCoverage(coverageDir, profile))
+
+	content, _ := os.ReadFile(profile)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"mode: set", "example.com/tests/main.go:3.13,5.2 1 1"}, lines[:2])
+	assert.Regexp(t, `^example\.com/function/main\.go:[0-9.,]+ 1 1$`, lines[2])
+}
+
+func TestMergeCoverage_DoesNothingWithoutCoverageData(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "coverage.out")
+	assert.NoError(t, os.WriteFile(profile, []byte("mode: set\n"), 0o644))
+
+	assert.NoError(t, mergeCoverage(filepath.Join(t.TempDir(), "missing"), profile))
+	assert.NoError(t, mergeCoverage(t.TempDir(), profile))
+
+	content, _ := os.ReadFile(profile)
+	assert.Equal(t, "mode: set\n", string(content))
+}
@@ -0,0 +1,56 @@
+package testcontainernetwork
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lambdaTaskDir is where AWS extracts the deployment package of a function
+const lambdaTaskDir = "/var/task"
+
+// checkDeployment checks that the function is deployed in only one way, and in a way that the runtime supports
+func (c *LambdaDockerContainer) checkDeployment() error {
+	var deployments []string
+	for _, deployment := range []struct {
+		name string
+		set  bool
+	}{
+		{"Executable", c.Config.Executable != ""},
+		{"Package", c.Config.Package != ""},
+		{"DeploymentPackage", c.Config.DeploymentPackage != ""},
+		{"Image", c.Config.Image != ""},
+	} {
+		if deployment.set {
+			deployments = append(deployments, deployment.name)
+		}
+	}
+	if len(deployments) > 1 {
+		return fmt.Errorf("a Lambda is deployed from only one of Executable, Package, DeploymentPackage and Image, but has %s", strings.Join(deployments, " and "))
+	}
+	if c.Config.Watch && c.Config.Executable == "" && c.Config.Package == "" {
+		return fmt.Errorf("only a Lambda deployed from an Executable or Package can be watched")
+	}
+	if c.Config.Image != "" && c.Config.Runtime == LambdaRuntimeGo1x {
+		return fmt.Errorf("the %s Lambda runtime can't be run from a container image", LambdaRuntimeGo1x)
+	}
+	if c.Config.Image != "" && c.Config.CoverageDir != "" {
+		return fmt.Errorf("coverage can't be collected from a Lambda container image")
+	}
+	return nil
+}
+
+// addDeploymentPackage arranges for the DeploymentPackage to be extracted into /var/task when the container is
+// created, returning a Closer for the zip file to be closed once it has been copied
+func (c *LambdaDockerContainer) addDeploymentPackage() (io.Closer, error) {
+	if c.Config.DeploymentPackage == "" {
+		return layerClosers(nil), nil
+	}
+	zipReader, err := zip.OpenReader(c.Config.DeploymentPackage)
+	if err != nil {
+		return nil, fmt.Errorf("opening Lambda deployment package %s: %w", c.Config.DeploymentPackage, err)
+	}
+	c.copyDirectoryOnStart(zipReader, lambdaTaskDir)
+	return zipReader, nil
+}
@@ -0,0 +1,81 @@
+package testcontainernetwork
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLambdaDockerContainer_ExtractsDeploymentPackageIntoTask(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		DeploymentPackage: zipArchive(t, map[string]string{"bootstrap": "#!function", "templates/receipt.txt": "receipt"}),
+	}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	calls := callsAsStrings(provider.Calls())
+	assert.Contains(t, calls, "copy to container lambda 10 bytes -> /var/task/bootstrap (755)")
+	assert.Contains(t, calls, "copy to container lambda 7 bytes -> /var/task/templates/receipt.txt (644)")
+	for _, call := range calls {
+		assert.NotContains(t, call, "/var/runtime/bootstrap")
+	}
+}
+
+func TestLambdaDockerContainer_ExtractsDeploymentPackageOnLegacyGo1xRuntime(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		DeploymentPackage: zipArchive(t, map[string]string{"orders": "#!function"}),
+		Handler:           "orders",
+		Runtime:           LambdaRuntimeGo1x,
+	}}
+	network := fakeNetwork(provider, lambdaContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.Contains(t, callsAsStrings(provider.Calls()), "copy to container lambda 10 bytes -> /var/task/orders (755)")
+	assert.Equal(t, "/var/task/orders", lambdaContainer.containerExecutable())
+}
+
+func TestLambdaDockerContainer_RunsContainerImageWithItsOwnHandler(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Image: "example.com/orders:ci"}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, "example.com/orders:ci", req.Image)
+	assert.Nil(t, req.Cmd)
+	assert.Equal(t, []string{"8080/tcp"}, req.ExposedPorts)
+	for _, call := range callsAsStrings(provider.Calls()) {
+		assert.NotContains(t, call, "copy to container")
+	}
+}
+
+func TestLambdaDockerContainer_OverridesHandlerOfContainerImage(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Image: "example.com/orders:ci", Handler: "refunds"}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, []string{"refunds"}, req.Cmd)
+}
+
+func TestLambdaDockerContainer_RejectsInvalidDeployments(t *testing.T) {
+	for _, test := range []struct {
+		config LambdaDockerContainerConfig
+		err    string
+	}{
+		{LambdaDockerContainerConfig{Executable: "main", DeploymentPackage: "function.zip"}, "a Lambda is deployed from only one of Executable, Package, DeploymentPackage and Image, but has Executable and DeploymentPackage"},
+		{LambdaDockerContainerConfig{Image: "example.com/orders:ci", Runtime: LambdaRuntimeGo1x}, "the go1.x Lambda runtime can't be run from a container image"},
+		{LambdaDockerContainerConfig{Image: "example.com/orders:ci", CoverageDir: "coverage"}, "coverage can't be collected from a Lambda container image"},
+		{LambdaDockerContainerConfig{DeploymentPackage: "missing.zip"}, "opening Lambda deployment package missing.zip"},
+	} {
+		provider := &FakeContainerProvider{}
+		network := fakeNetwork(provider, &LambdaDockerContainer{Config: test.config})
+
+		err := network.StartWithDelay(0)
+
+		assert.ErrorContains(t, err, test.err)
+	}
+}
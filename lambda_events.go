@@ -0,0 +1,432 @@
+package testcontainernetwork
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The builders below create the events that AWS services invoke Lambdas with, filled in as AWS would fill them in for
+// the account of the Lambda container and the region given to WithRegion, which should be the Region of the Lambda
+// and, like it, defaults to eu-west-1.  Like NetworkOfDockerContainers, each With method returns a copy, so a builder
+// can be used as a template for several events
+
+const eventAccountId = "000000000000"
+
+// regionOrDefault is the region of a Lambda whose Config.Region is region, and so of the events that it is invoked with
+func regionOrDefault(region string) string {
+	if region == "" {
+		return defaultLambdaRegion
+	}
+	return region
+}
+
+// withEntry returns a copy of m with key set to value
+func withEntry[V any](m map[string]V, key string, value V) map[string]V {
+	m = maps.Clone(m)
+	if m == nil {
+		m = map[string]V{}
+	}
+	m[key] = value
+	return m
+}
+
+// ApiGatewayV1RequestBuilder builds the events.APIGatewayProxyRequest of an API Gateway REST API
+type ApiGatewayV1RequestBuilder struct {
+	request events.APIGatewayProxyRequest
+}
+
+func NewApiGatewayV1Request(method string, path string) ApiGatewayV1RequestBuilder {
+	return ApiGatewayV1RequestBuilder{request: events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Path:       path,
+		Resource:   path,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:    eventAccountId,
+			Stage:        "test",
+			HTTPMethod:   method,
+			Path:         path,
+			ResourcePath: path,
+			RequestTime:  time.Now().UTC().Format("02/Jan/2006:15:04:05 -0700"),
+		},
+	}}
+}
+
+// WithResource sets the resource that the request matched, such as /orders/{id}
+func (b ApiGatewayV1RequestBuilder) WithResource(resource string) ApiGatewayV1RequestBuilder {
+	b.request.Resource = resource
+	b.request.RequestContext.ResourcePath = resource
+	return b
+}
+
+func (b ApiGatewayV1RequestBuilder) WithHeader(name string, value string) ApiGatewayV1RequestBuilder {
+	b.request.Headers = withEntry(b.request.Headers, name, value)
+	b.request.MultiValueHeaders = withEntry(b.request.MultiValueHeaders, name, append(slices.Clip(b.request.MultiValueHeaders[name]), value))
+	return b
+}
+
+func (b ApiGatewayV1RequestBuilder) WithQueryParameter(name string, value string) ApiGatewayV1RequestBuilder {
+	b.request.QueryStringParameters = withEntry(b.request.QueryStringParameters, name, value)
+	b.request.MultiValueQueryStringParameters = withEntry(b.request.MultiValueQueryStringParameters, name, append(slices.Clip(b.request.MultiValueQueryStringParameters[name]), value))
+	return b
+}
+
+func (b ApiGatewayV1RequestBuilder) WithPathParameter(name string, value string) ApiGatewayV1RequestBuilder {
+	b.request.PathParameters = withEntry(b.request.PathParameters, name, value)
+	return b
+}
+
+func (b ApiGatewayV1RequestBuilder) WithBody(body string) ApiGatewayV1RequestBuilder {
+	b.request.Body = body
+	b.request.IsBase64Encoded = false
+	return b
+}
+
+// WithBinaryBody sets the body to the base64 encoding of body, as API Gateway does for binary media types
+func (b ApiGatewayV1RequestBuilder) WithBinaryBody(body []byte) ApiGatewayV1RequestBuilder {
+	b.request.Body = base64.StdEncoding.EncodeToString(body)
+	b.request.IsBase64Encoded = true
+	return b
+}
+
+func (b ApiGatewayV1RequestBuilder) Build() events.APIGatewayProxyRequest {
+	return b.request
+}
+
+// ApiGatewayV2RequestBuilder builds the events.APIGatewayV2HTTPRequest of an API Gateway HTTP API or a Lambda function
+// URL, using payload format version 2.0
+type ApiGatewayV2RequestBuilder struct {
+	request events.APIGatewayV2HTTPRequest
+}
+
+func NewApiGatewayV2Request(method string, path string) ApiGatewayV2RequestBuilder {
+	now := time.Now().UTC()
+	return ApiGatewayV2RequestBuilder{request: events.APIGatewayV2HTTPRequest{
+		Version:  "2.0",
+		RouteKey: "$default",
+		RawPath:  path,
+		Headers:  map[string]string{},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:  "$default",
+			AccountID: eventAccountId,
+			Stage:     "$default",
+			Time:      now.Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch: now.UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:   method,
+				Path:     path,
+				Protocol: "HTTP/1.1",
+				SourceIP: "127.0.0.1",
+			},
+		},
+	}}
+}
+
+// WithRouteKey sets the route that the request matched, such as "GET /orders/{id}"
+func (b ApiGatewayV2RequestBuilder) WithRouteKey(routeKey string) ApiGatewayV2RequestBuilder {
+	b.request.RouteKey = routeKey
+	b.request.RequestContext.RouteKey = routeKey
+	return b
+}
+
+// WithHeader sets a header, joining repeated headers with commas as API Gateway does
+func (b ApiGatewayV2RequestBuilder) WithHeader(name string, value string) ApiGatewayV2RequestBuilder {
+	name = strings.ToLower(name)
+	if existing, ok := b.request.Headers[name]; ok {
+		value = existing + "," + value
+	}
+	b.request.Headers = withEntry(b.request.Headers, name, value)
+	return b
+}
+
+func (b ApiGatewayV2RequestBuilder) WithCookie(cookie string) ApiGatewayV2RequestBuilder {
+	b.request.Cookies = append(slices.Clip(b.request.Cookies), cookie)
+	return b
+}
+
+// WithQueryParameter adds a query string parameter, joining repeated parameters with commas as API Gateway does
+func (b ApiGatewayV2RequestBuilder) WithQueryParameter(name string, value string) ApiGatewayV2RequestBuilder {
+	query := url.Values{}
+	if b.request.RawQueryString != "" {
+		query, _ = url.ParseQuery(b.request.RawQueryString)
+	}
+	query.Add(name, value)
+	b.request.RawQueryString = query.Encode()
+	b.request.QueryStringParameters = withEntry(b.request.QueryStringParameters, name, strings.Join(query[name], ","))
+	return b
+}
+
+func (b ApiGatewayV2RequestBuilder) WithPathParameter(name string, value string) ApiGatewayV2RequestBuilder {
+	b.request.PathParameters = withEntry(b.request.PathParameters, name, value)
+	return b
+}
+
+func (b ApiGatewayV2RequestBuilder) WithBody(body string) ApiGatewayV2RequestBuilder {
+	b.request.Body = body
+	b.request.IsBase64Encoded = false
+	return b
+}
+
+// WithBinaryBody sets the body to the base64 encoding of body, as API Gateway does for bodies that aren't text
+func (b ApiGatewayV2RequestBuilder) WithBinaryBody(body []byte) ApiGatewayV2RequestBuilder {
+	b.request.Body = base64.StdEncoding.EncodeToString(body)
+	b.request.IsBase64Encoded = true
+	return b
+}
+
+func (b ApiGatewayV2RequestBuilder) Build() events.APIGatewayV2HTTPRequest {
+	return b.request
+}
+
+// SqsEventBuilder builds the events.SQSEvent that an SQS event source mapping invokes a Lambda with
+type SqsEventBuilder struct {
+	queueName string
+	region    string
+	records   []events.SQSMessage
+}
+
+func NewSqsEvent(queueName string) SqsEventBuilder {
+	return SqsEventBuilder{queueName: queueName}
+}
+
+// WithRegion sets the region of the queue
+func (b SqsEventBuilder) WithRegion(region string) SqsEventBuilder {
+	b.region = region
+	return b
+}
+
+func (b SqsEventBuilder) WithMessage(body string) SqsEventBuilder {
+	return b.WithMessageAttributes(body, nil)
+}
+
+func (b SqsEventBuilder) WithMessageAttributes(body string, attributes map[string]events.SQSMessageAttribute) SqsEventBuilder {
+	messageId := fmt.Sprintf("00000000-0000-0000-0000-%012d", len(b.records)+1)
+	b.records = append(slices.Clip(b.records), events.SQSMessage{
+		MessageId:     messageId,
+		ReceiptHandle: "receipt-handle-" + messageId,
+		Body:          body,
+		Attributes: map[string]string{
+			"ApproximateReceiveCount":          "1",
+			"SentTimestamp":                    fmt.Sprint(time.Now().UnixMilli()),
+			"ApproximateFirstReceiveTimestamp": fmt.Sprint(time.Now().UnixMilli()),
+		},
+		MessageAttributes: attributes,
+		EventSource:       "aws:sqs",
+	})
+	return b
+}
+
+func (b SqsEventBuilder) Build() events.SQSEvent {
+	region := regionOrDefault(b.region)
+	records := slices.Clone(b.records)
+	for i := range records {
+		records[i].EventSourceARN = fmt.Sprintf("arn:aws:sqs:%s:%s:%s", region, eventAccountId, b.queueName)
+		records[i].AWSRegion = region
+	}
+	return events.SQSEvent{Records: records}
+}
+
+// SnsEventBuilder builds the events.SNSEvent that an SNS subscription invokes a Lambda with
+type SnsEventBuilder struct {
+	topicName string
+	region    string
+	records   []events.SNSEventRecord
+}
+
+func NewSnsEvent(topicName string) SnsEventBuilder {
+	return SnsEventBuilder{topicName: topicName}
+}
+
+// WithRegion sets the region of the topic
+func (b SnsEventBuilder) WithRegion(region string) SnsEventBuilder {
+	b.region = region
+	return b
+}
+
+func (b SnsEventBuilder) WithMessage(subject string, message string) SnsEventBuilder {
+	return b.WithMessageAttributes(subject, message, nil)
+}
+
+func (b SnsEventBuilder) WithMessageAttributes(subject string, message string, attributes map[string]interface{}) SnsEventBuilder {
+	messageId := fmt.Sprintf("00000000-0000-0000-0000-%012d", len(b.records)+1)
+	b.records = append(slices.Clip(b.records), events.SNSEventRecord{
+		EventVersion: "1.0",
+		EventSource:  "aws:sns",
+		SNS: events.SNSEntity{
+			Type:              "Notification",
+			MessageID:         messageId,
+			Subject:           subject,
+			Message:           message,
+			Timestamp:         time.Now().UTC(),
+			SignatureVersion:  "1",
+			MessageAttributes: attributes,
+		},
+	})
+	return b
+}
+
+func (b SnsEventBuilder) Build() events.SNSEvent {
+	topicArn := fmt.Sprintf("arn:aws:sns:%s:%s:%s", regionOrDefault(b.region), eventAccountId, b.topicName)
+	records := slices.Clone(b.records)
+	for i := range records {
+		records[i].EventSubscriptionArn = topicArn + ":subscription"
+		records[i].SNS.TopicArn = topicArn
+	}
+	return events.SNSEvent{Records: records}
+}
+
+// S3EventBuilder builds the events.S3Event of notifications of changes to the objects in a bucket
+type S3EventBuilder struct {
+	bucket  string
+	region  string
+	records []events.S3EventRecord
+}
+
+func NewS3Event(bucket string) S3EventBuilder {
+	return S3EventBuilder{bucket: bucket}
+}
+
+// WithRegion sets the region of the bucket
+func (b S3EventBuilder) WithRegion(region string) S3EventBuilder {
+	b.region = region
+	return b
+}
+
+func (b S3EventBuilder) WithObjectCreated(key string, size int64) S3EventBuilder {
+	return b.withRecord("ObjectCreated:Put", events.S3Object{Key: key, URLDecodedKey: key, Size: size})
+}
+
+func (b S3EventBuilder) WithObjectRemoved(key string) S3EventBuilder {
+	return b.withRecord("ObjectRemoved:Delete", events.S3Object{Key: key, URLDecodedKey: key})
+}
+
+func (b S3EventBuilder) withRecord(eventName string, object events.S3Object) S3EventBuilder {
+	object.Key = url.QueryEscape(object.Key)
+	b.records = append(slices.Clip(b.records), events.S3EventRecord{
+		EventVersion: "2.1",
+		EventSource:  "aws:s3",
+		EventTime:    time.Now().UTC(),
+		EventName:    eventName,
+		S3: events.S3Entity{
+			SchemaVersion: "1.0",
+			Bucket: events.S3Bucket{
+				Name: b.bucket,
+				Arn:  "arn:aws:s3:::" + b.bucket,
+			},
+			Object: object,
+		},
+	})
+	return b
+}
+
+func (b S3EventBuilder) Build() events.S3Event {
+	records := slices.Clone(b.records)
+	for i := range records {
+		records[i].AWSRegion = regionOrDefault(b.region)
+	}
+	return events.S3Event{Records: records}
+}
+
+// EventBridgeEventBuilder builds the events.EventBridgeEvent that an EventBridge rule invokes a Lambda with
+type EventBridgeEventBuilder struct {
+	event events.EventBridgeEvent
+}
+
+func NewEventBridgeEvent(source string, detailType string) EventBridgeEventBuilder {
+	return EventBridgeEventBuilder{event: events.EventBridgeEvent{
+		Version:    "0",
+		ID:         "00000000-0000-0000-0000-000000000001",
+		DetailType: detailType,
+		Source:     source,
+		AccountID:  eventAccountId,
+		Time:       time.Now().UTC(),
+		Region:     defaultLambdaRegion,
+		Resources:  []string{},
+		Detail:     []byte("{}"),
+	}}
+}
+
+// WithRegion sets the region of the event bus
+func (b EventBridgeEventBuilder) WithRegion(region string) EventBridgeEventBuilder {
+	b.event.Region = regionOrDefault(region)
+	return b
+}
+
+// WithDetail sets the detail of the event, which must be a JSON object
+func (b EventBridgeEventBuilder) WithDetail(detail string) EventBridgeEventBuilder {
+	b.event.Detail = []byte(detail)
+	return b
+}
+
+func (b EventBridgeEventBuilder) WithResource(arn string) EventBridgeEventBuilder {
+	b.event.Resources = append(slices.Clip(b.event.Resources), arn)
+	return b
+}
+
+func (b EventBridgeEventBuilder) Build() events.EventBridgeEvent {
+	return b.event
+}
+
+// DynamoDbStreamEventBuilder builds the events.DynamoDBEvent of the changes to the items in a table that a DynamoDB
+// stream invokes a Lambda with, as if the stream view type were NEW_AND_OLD_IMAGES
+type DynamoDbStreamEventBuilder struct {
+	tableName string
+	region    string
+	records   []events.DynamoDBEventRecord
+}
+
+func NewDynamoDbStreamEvent(tableName string) DynamoDbStreamEventBuilder {
+	return DynamoDbStreamEventBuilder{tableName: tableName}
+}
+
+// WithRegion sets the region of the table
+func (b DynamoDbStreamEventBuilder) WithRegion(region string) DynamoDbStreamEventBuilder {
+	b.region = region
+	return b
+}
+
+func (b DynamoDbStreamEventBuilder) WithInsert(keys map[string]events.DynamoDBAttributeValue, newImage map[string]events.DynamoDBAttributeValue) DynamoDbStreamEventBuilder {
+	return b.withRecord(events.DynamoDBOperationTypeInsert, keys, newImage, nil)
+}
+
+func (b DynamoDbStreamEventBuilder) WithModify(keys map[string]events.DynamoDBAttributeValue, oldImage map[string]events.DynamoDBAttributeValue, newImage map[string]events.DynamoDBAttributeValue) DynamoDbStreamEventBuilder {
+	return b.withRecord(events.DynamoDBOperationTypeModify, keys, newImage, oldImage)
+}
+
+func (b DynamoDbStreamEventBuilder) WithRemove(keys map[string]events.DynamoDBAttributeValue, oldImage map[string]events.DynamoDBAttributeValue) DynamoDbStreamEventBuilder {
+	return b.withRecord(events.DynamoDBOperationTypeRemove, keys, nil, oldImage)
+}
+
+func (b DynamoDbStreamEventBuilder) withRecord(operation events.DynamoDBOperationType, keys map[string]events.DynamoDBAttributeValue, newImage map[string]events.DynamoDBAttributeValue, oldImage map[string]events.DynamoDBAttributeValue) DynamoDbStreamEventBuilder {
+	sequenceNumber := fmt.Sprintf("%021d", len(b.records)+1)
+	b.records = append(slices.Clip(b.records), events.DynamoDBEventRecord{
+		EventID:      sequenceNumber,
+		EventName:    string(operation),
+		EventSource:  "aws:dynamodb",
+		EventVersion: "1.1",
+		Change: events.DynamoDBStreamRecord{
+			ApproximateCreationDateTime: events.SecondsEpochTime{Time: time.Now().UTC().Truncate(time.Second)},
+			Keys:                        keys,
+			NewImage:                    newImage,
+			OldImage:                    oldImage,
+			SequenceNumber:              sequenceNumber,
+			StreamViewType:              string(events.DynamoDBStreamViewTypeNewAndOldImages),
+		},
+	})
+	return b
+}
+
+func (b DynamoDbStreamEventBuilder) Build() events.DynamoDBEvent {
+	region := regionOrDefault(b.region)
+	records := slices.Clone(b.records)
+	for i := range records {
+		records[i].AWSRegion = region
+		records[i].EventSourceArn = fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s/stream/2024-01-01T00:00:00.000", region, eventAccountId, b.tableName)
+	}
+	return events.DynamoDBEvent{Records: records}
+}
@@ -0,0 +1,78 @@
+package testcontainernetwork
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApiGatewayV1RequestBuilder_IsATemplate(t *testing.T) {
+	template := NewApiGatewayV1Request("GET", "/orders/42").
+		WithResource("/orders/{id}").
+		WithPathParameter("id", "42").
+		WithHeader("Accept", "application/json")
+
+	withQuery := template.WithQueryParameter("expand", "items").WithQueryParameter("expand", "customer").Build()
+	withBody := template.WithBinaryBody([]byte("hello")).Build()
+
+	assert.Equal(t, "/orders/{id}", withQuery.Resource)
+	assert.Equal(t, "42", withQuery.PathParameters["id"])
+	assert.Equal(t, []string{"items", "customer"}, withQuery.MultiValueQueryStringParameters["expand"])
+	assert.Empty(t, withBody.QueryStringParameters)
+	assert.Equal(t, "aGVsbG8=", withBody.Body)
+	assert.True(t, withBody.IsBase64Encoded)
+}
+
+func TestApiGatewayV2RequestBuilder(t *testing.T) {
+	request := NewApiGatewayV2Request("POST", "/orders").
+		WithHeader("X-Trace", "a").
+		WithHeader("X-Trace", "b").
+		WithQueryParameter("dryRun", "true").
+		WithBody(`{"item": "book"}`).
+		Build()
+
+	assert.Equal(t, "2.0", request.Version)
+	assert.Equal(t, "POST", request.RequestContext.HTTP.Method)
+	assert.Equal(t, "a,b", request.Headers["x-trace"])
+	assert.Equal(t, "dryRun=true", request.RawQueryString)
+	assert.Equal(t, "true", request.QueryStringParameters["dryRun"])
+}
+
+func TestSqsEventBuilder(t *testing.T) {
+	event := NewSqsEvent("orders").WithMessage("one").WithMessage("two").Build()
+
+	assert.Len(t, event.Records, 2)
+	assert.Equal(t, "arn:aws:sqs:eu-west-1:000000000000:orders", event.Records[1].EventSourceARN)
+	assert.Equal(t, "two", event.Records[1].Body)
+	assert.NotEqual(t, event.Records[0].MessageId, event.Records[1].MessageId)
+}
+
+func TestEventBuilders_PutEventsInRegionOfLambda(t *testing.T) {
+	sqsEvent := NewSqsEvent("orders").WithMessage("one").WithRegion("us-east-1").Build()
+	snsEvent := NewSnsEvent("refunds").WithRegion("us-east-1").WithMessage("refund", "42").Build()
+	dynamoDbEvent := NewDynamoDbStreamEvent("orders").WithRegion("us-east-1").
+		WithInsert(map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("42")}, nil).
+		Build()
+
+	assert.Equal(t, "us-east-1", sqsEvent.Records[0].AWSRegion)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:000000000000:orders", sqsEvent.Records[0].EventSourceARN)
+	assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:refunds", snsEvent.Records[0].SNS.TopicArn)
+	assert.Equal(t, "us-east-1", dynamoDbEvent.Records[0].AWSRegion)
+	assert.Equal(t, "arn:aws:dynamodb:us-east-1:000000000000:table/orders/stream/2024-01-01T00:00:00.000", dynamoDbEvent.Records[0].EventSourceArn)
+	assert.Equal(t, "us-east-1", NewS3Event("invoices").WithObjectRemoved("42.pdf").WithRegion("us-east-1").Build().Records[0].AWSRegion)
+	assert.Equal(t, "eu-west-1", NewEventBridgeEvent("orders", "OrderPlaced").WithRegion("").Build().Region)
+}
+
+func TestDynamoDbStreamEventBuilder(t *testing.T) {
+	keys := map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("42")}
+
+	event := NewDynamoDbStreamEvent("orders").
+		WithInsert(keys, map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("42")}).
+		WithRemove(keys, map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("42")}).
+		Build()
+
+	assert.Equal(t, "INSERT", event.Records[0].EventName)
+	assert.Nil(t, event.Records[0].Change.OldImage)
+	assert.Equal(t, "REMOVE", event.Records[1].EventName)
+	assert.Equal(t, "42", event.Records[1].Change.OldImage["id"].String())
+}
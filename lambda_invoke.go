@@ -0,0 +1,157 @@
+package testcontainernetwork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// LambdaResponse is the response to an invocation of the Lambda: Payload is what the function returned or, if
+// FunctionError is set, the error it returned or the runtime reported
+type LambdaResponse struct {
+	StatusCode    int
+	Payload       []byte
+	FunctionError string
+}
+
+// Unmarshal decodes the JSON payload of the response into v
+func (r LambdaResponse) Unmarshal(v any) error {
+	if err := json.Unmarshal(r.Payload, v); err != nil {
+		return fmt.Errorf("unmarshalling Lambda response: %w", err)
+	}
+	return nil
+}
+
+// LambdaFunctionError is returned by Invoke when the function returns an error, or panics, or the runtime fails to run
+// it; Kind is the value of X-Amz-Function-Error, and StackTrace is in whatever form the runtime reports it
+type LambdaFunctionError struct {
+	Kind         string          `json:"-"`
+	ErrorType    string          `json:"errorType"`
+	ErrorMessage string          `json:"errorMessage"`
+	StackTrace   json.RawMessage `json:"stackTrace,omitempty"`
+}
+
+func (e *LambdaFunctionError) Error() string {
+	if e.ErrorType == "" {
+		return fmt.Sprintf("Lambda function error: %s", e.ErrorMessage)
+	}
+	return fmt.Sprintf("Lambda function error: %s: %s", e.ErrorType, e.ErrorMessage)
+}
+
+// Invoke invokes the Lambda synchronously with event, which is marshalled to JSON unless it is already []byte or
+// json.RawMessage, on the first of its Instances to be free.  If the function fails, the response is returned along
+// with a *LambdaFunctionError
+func (c *LambdaDockerContainer) Invoke(ctx context.Context, event any) (LambdaResponse, error) {
+	result := c.invoke(ctx, event)
+	return result.Response, result.Err
+}
+
+// invokeLambda posts event to the Invoke API at invocationUrl
+func invokeLambda(ctx context.Context, invocationUrl string, event any) (LambdaResponse, error) {
+	var payload []byte
+	switch e := event.(type) {
+	case []byte:
+		payload = e
+	case json.RawMessage:
+		payload = e
+	default:
+		var err error
+		if payload, err = json.Marshal(event); err != nil {
+			return LambdaResponse{}, fmt.Errorf("marshalling Lambda event: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, invocationUrl, bytes.NewReader(payload))
+	if err != nil {
+		return LambdaResponse{}, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var client = http.Client{
+		Timeout: time.Minute * 15,
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return LambdaResponse{}, fmt.Errorf("invoking Lambda: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return LambdaResponse{}, fmt.Errorf("reading Lambda response: %w", err)
+	}
+	response := LambdaResponse{StatusCode: res.StatusCode, Payload: body, FunctionError: res.Header.Get("X-Amz-Function-Error")}
+	if res.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("invoking Lambda: unexpected status %d: %s", res.StatusCode, body)
+	}
+	if functionError, ok := functionErrorIn(response); ok {
+		if response.FunctionError == "" {
+			response.FunctionError = "Unhandled"
+		}
+		functionError.Kind = response.FunctionError
+		return response, functionError
+	}
+	return response, nil
+}
+
+// Lambda returns the Lambda in the network with the function name functionName
+func (n *NetworkOfDockerContainers) Lambda(functionName string) (*LambdaDockerContainer, error) {
+	for _, dockerContainer := range n.dockerContainers {
+		if lambdaContainer, ok := dockerContainer.(*LambdaDockerContainer); ok && lambdaContainer.FunctionName() == functionName {
+			return lambdaContainer, nil
+		}
+	}
+	return nil, fmt.Errorf("no Lambda function named %s in the network", functionName)
+}
+
+// InvokeLambda invokes the Lambda in the network with the function name functionName with event
+func (n *NetworkOfDockerContainers) InvokeLambda(ctx context.Context, functionName string, event any) (LambdaResponse, error) {
+	lambdaContainer, err := n.Lambda(functionName)
+	if err != nil {
+		return LambdaResponse{}, err
+	}
+	return lambdaContainer.Invoke(ctx, event)
+}
+
+// InvokeAs invokes the Lambda with event and decodes the function's response into a T
+func InvokeAs[T any](ctx context.Context, c *LambdaDockerContainer, event any) (T, error) {
+	var result T
+	response, err := c.Invoke(ctx, event)
+	if err != nil {
+		return result, err
+	}
+	err = response.Unmarshal(&result)
+	return result, err
+}
+
+// functionErrorIn finds the error in a response that is flagged as a function error, or that, as with some emulators
+// that don't set X-Amz-Function-Error, consists only of an error
+func functionErrorIn(response LambdaResponse) (*LambdaFunctionError, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response.Payload, &fields); err != nil {
+		if response.FunctionError != "" {
+			return &LambdaFunctionError{ErrorMessage: string(response.Payload)}, true
+		}
+		return nil, false
+	}
+	if response.FunctionError == "" {
+		if _, ok := fields["errorMessage"]; !ok {
+			return nil, false
+		}
+		for field := range fields {
+			if field != "errorType" && field != "errorMessage" && field != "stackTrace" {
+				return nil, false
+			}
+		}
+	}
+	var functionError LambdaFunctionError
+	if err := json.Unmarshal(response.Payload, &functionError); err != nil {
+		functionError.ErrorMessage = string(response.Payload)
+	}
+	return &functionError, true
+}
@@ -0,0 +1,65 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func lambdaServer(t *testing.T, functionError string, response string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "World"}`, string(body))
+		if functionError != "" {
+			w.Header().Set("X-Amz-Function-Error", functionError)
+		}
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInvokeLambda_ReturnsPayload(t *testing.T) {
+	server := lambdaServer(t, "", `{"greeting": "Hello World!"}`)
+
+	response, err := invokeLambda(context.Background(), server.URL, map[string]string{"name": "World"})
+
+	assert.NoError(t, err)
+	var greeting struct{ Greeting string }
+	assert.NoError(t, response.Unmarshal(&greeting))
+	assert.Equal(t, "Hello World!", greeting.Greeting)
+}
+
+func TestInvokeLambda_ReturnsFunctionError(t *testing.T) {
+	server := lambdaServer(t, "Unhandled", `{"errorMessage": "no such user", "errorType": "errorString"}`)
+
+	response, err := invokeLambda(context.Background(), server.URL, []byte(`{"name": "World"}`))
+
+	var functionError *LambdaFunctionError
+	assert.True(t, errors.As(err, &functionError))
+	assert.Equal(t, "Unhandled", functionError.Kind)
+	assert.Equal(t, "errorString", functionError.ErrorType)
+	assert.Equal(t, "no such user", functionError.ErrorMessage)
+	assert.Equal(t, "Unhandled", response.FunctionError)
+}
+
+func TestInvokeLambda_RecognisesFunctionErrorWithoutHeader(t *testing.T) {
+	server := lambdaServer(t, "", `{"errorMessage": "no such user", "errorType": "errorString", "stackTrace": []}`)
+
+	_, err := invokeLambda(context.Background(), server.URL, map[string]string{"name": "World"})
+
+	assert.EqualError(t, err, "Lambda function error: errorString: no such user")
+}
+
+func TestInvokeLambda_DoesNotMistakeResponseWithErrorMessageForFunctionError(t *testing.T) {
+	server := lambdaServer(t, "", `{"errorMessage": "validation failed", "statusCode": 400}`)
+
+	response, err := invokeLambda(context.Background(), server.URL, map[string]string{"name": "World"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, response.FunctionError)
+}
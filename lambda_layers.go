@@ -0,0 +1,79 @@
+package testcontainernetwork
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// lambdaLayersDir is where AWS extracts the layers of a function, and lambdaExtensionsDir where the runtime finds the
+// extensions that it starts alongside the function
+const (
+	lambdaLayersDir     = "/opt"
+	lambdaExtensionsDir = "/opt/extensions"
+)
+
+// addLayers arranges for the Layers to be copied into /opt when the container is created, in order, so that later
+// layers overwrite the files of earlier ones, returning a Closer for the zip files among them to be closed once they
+// have been copied
+func (c *LambdaDockerContainer) addLayers() (io.Closer, error) {
+	var closers layerClosers
+	for _, layer := range c.Config.Layers {
+		fsys, closer, err := layerFS(layer)
+		if err != nil {
+			closers.Close()
+			return nil, err
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		c.copyDirectoryOnStart(fsys, lambdaLayersDir)
+	}
+	return closers, nil
+}
+
+// layerFS opens a layer, which is either a directory or a zip file as published to AWS
+func layerFS(layer string) (fs.FS, io.Closer, error) {
+	info, err := os.Stat(layer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening Lambda layer: %w", err)
+	}
+	if info.IsDir() {
+		return os.DirFS(layer), nil, nil
+	}
+	if !strings.EqualFold(filepath.Ext(layer), ".zip") {
+		return nil, nil, fmt.Errorf("layer %s is neither a directory nor a zip file", layer)
+	}
+	zipReader, err := zip.OpenReader(layer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening Lambda layer %s: %w", layer, err)
+	}
+	return zipReader, zipReader, nil
+}
+
+type layerClosers []io.Closer
+
+func (c layerClosers) Close() error {
+	var errs []error
+	for _, closer := range c {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// copyExtensions copies the Extensions into /opt/extensions, where the runtime starts them before the function
+func (c *LambdaDockerContainer) copyExtensions(ctx context.Context) error {
+	for _, extension := range c.Config.Extensions {
+		if err := c.testContainer.CopyFileToContainer(ctx, extension, path.Join(lambdaExtensionsDir, filepath.Base(extension)), executableFileMode); err != nil {
+			return fmt.Errorf("copying extension to docker container: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package testcontainernetwork
+
+import (
+	"archive/zip"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zipArchive writes a zip file of the files, making those that start with #! executable
+func zipArchive(t *testing.T, files map[string]string) string {
+	archive := filepath.Join(t.TempDir(), "archive.zip")
+	f, err := os.Create(archive)
+	assert.NoError(t, err)
+	defer f.Close()
+	zipWriter := zip.NewWriter(f)
+	for name, content := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0o644)
+		if strings.HasPrefix(content, "#!") {
+			header.SetMode(0o755)
+		}
+		w, err := zipWriter.CreateHeader(header)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zipWriter.Close())
+	return archive
+}
+
+func TestLambdaDockerContainer_CopiesLayersAndExtensions(t *testing.T) {
+	directoryLayer := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(directoryLayer, "config"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(directoryLayer, "config", "app.json"), []byte(`{"env": "test"}`), 0o644))
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable: "main",
+		Layers:     []string{directoryLayer, zipArchive(t, map[string]string{"certs/ca-bundle.pem": "certificate", "bin/helper": "#!/bin/sh\n"})},
+		Extensions: []string{"extensions/secrets-cache"},
+	}})
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	calls := callsAsStrings(provider.Calls())
+	assert.Contains(t, calls, "copy to container lambda 15 bytes -> /opt/config/app.json (644)")
+	assert.Contains(t, calls, "copy to container lambda 11 bytes -> /opt/certs/ca-bundle.pem (644)")
+	assert.Contains(t, calls, "copy to container lambda 10 bytes -> /opt/bin/helper (755)")
+	assert.Contains(t, calls, "copy to container lambda extensions/secrets-cache -> /opt/extensions/secrets-cache (755)")
+}
+
+func TestLambdaDockerContainer_RejectsLayerThatIsNotDirectoryOrZip(t *testing.T) {
+	layer := filepath.Join(t.TempDir(), "layer.tar")
+	assert.NoError(t, os.WriteFile(layer, []byte("tar"), 0o644))
+	network := fakeNetwork(&FakeContainerProvider{}, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", Layers: []string{layer}}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "layer.tar is neither a directory nor a zip file")
+}
+
+func TestLambdaDockerContainer_RejectsExtensionsOnLegacyGo1xRuntime(t *testing.T) {
+	network := fakeNetwork(&FakeContainerProvider{}, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{
+		Executable: "main",
+		Runtime:    LambdaRuntimeGo1x,
+		Extensions: []string{"extensions/secrets-cache"},
+	}})
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "the go1.x Lambda runtime doesn't support extensions")
+}
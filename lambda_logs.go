@@ -0,0 +1,154 @@
+package testcontainernetwork
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LambdaInvocationLog is what the runtime logged for one invocation of the function: the lines that the function
+// logged between the START and END lines, and the figures from the REPORT line, which are zero until it is logged
+type LambdaInvocationLog struct {
+	RequestId string
+	Version   string
+	Lines     []string
+	// Duration is how long the invocation took and BilledDuration what AWS would bill for it; InitDuration is how long
+	// the function took to initialise, which is only reported for the first invocation after a cold start
+	Duration       time.Duration
+	BilledDuration time.Duration
+	InitDuration   time.Duration
+	// MemorySize and MaxMemoryUsed are in MB
+	MemorySize    int
+	MaxMemoryUsed int
+	// Reported is whether the REPORT line has been logged, after which the invocation is finished
+	Reported bool
+}
+
+// Output is the lines that the function logged, joined by newlines
+func (l LambdaInvocationLog) Output() string {
+	return strings.Join(l.Lines, "\n")
+}
+
+// Invocations parses the container's log into the invocations of the function, in the order they started
+func (c *LambdaDockerContainer) Invocations() ([]LambdaInvocationLog, error) {
+	buffer, err := c.Log()
+	if err != nil {
+		return nil, err
+	}
+	return parseLambdaLog(buffer)
+}
+
+// Invocation returns the log of the invocation with the given request ID
+func (c *LambdaDockerContainer) Invocation(requestId string) (LambdaInvocationLog, error) {
+	invocations, err := c.Invocations()
+	if err != nil {
+		return LambdaInvocationLog{}, err
+	}
+	for _, invocation := range invocations {
+		if invocation.RequestId == requestId {
+			return invocation, nil
+		}
+	}
+	return LambdaInvocationLog{}, fmt.Errorf("no invocation with request ID %s in the Lambda log", requestId)
+}
+
+// LastInvocation returns the log of the invocation that started last
+func (c *LambdaDockerContainer) LastInvocation() (LambdaInvocationLog, error) {
+	invocations, err := c.Invocations()
+	if err != nil {
+		return LambdaInvocationLog{}, err
+	}
+	if len(invocations) == 0 {
+		return LambdaInvocationLog{}, errors.New("no invocations in the Lambda log")
+	}
+	return invocations[len(invocations)-1], nil
+}
+
+// parseLambdaLog parses START, END and REPORT lines, as logged by AWS and both emulators, into invocations.  Lines
+// outside an invocation, and those that the Runtime Interface Emulator logs about itself, are skipped
+func parseLambdaLog(r io.Reader) ([]LambdaInvocationLog, error) {
+	var invocations []LambdaInvocationLog
+	indexes := map[string]int{}
+	current := -1
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("reading Lambda log: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "START RequestId: "):
+			requestId, fields := lambdaLogLineFields(line, "START")
+			indexes[requestId] = len(invocations)
+			current = len(invocations)
+			invocations = append(invocations, LambdaInvocationLog{RequestId: requestId, Version: fields["Version"]})
+		case strings.HasPrefix(line, "END RequestId: "):
+			current = -1
+		case strings.HasPrefix(line, "REPORT RequestId: "):
+			requestId, fields := lambdaLogLineFields(line, "REPORT")
+			if i, ok := indexes[requestId]; ok {
+				invocations[i].report(fields)
+			}
+			current = -1
+		case current >= 0 && !strings.Contains(line, " (rapid) "):
+			if line != "" || err == nil {
+				invocations[current].Lines = append(invocations[current].Lines, line)
+			}
+		}
+		if err != nil {
+			return invocations, nil
+		}
+	}
+}
+
+// lambdaLogLineFields splits a line such as "REPORT RequestId: 1234	Duration: 1.50 ms	Memory Size: 128 MB" into
+// its request ID and its other fields
+func lambdaLogLineFields(line string, kind string) (string, map[string]string) {
+	fields := map[string]string{}
+	var requestId string
+	for _, field := range strings.FieldsFunc(strings.TrimPrefix(line, kind+" "), func(r rune) bool { return r == '\t' }) {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), ": ")
+		if name == "RequestId" {
+			// START lines put the version after the request ID with a space rather than a tab
+			requestId, value, _ = strings.Cut(value, " ")
+			if version, ok := strings.CutPrefix(value, "Version: "); ok {
+				fields["Version"] = version
+			}
+			continue
+		}
+		fields[name] = value
+	}
+	return requestId, fields
+}
+
+func (l *LambdaInvocationLog) report(fields map[string]string) {
+	l.Reported = true
+	l.Duration = lambdaLogDuration(fields["Duration"])
+	l.BilledDuration = lambdaLogDuration(fields["Billed Duration"])
+	l.InitDuration = lambdaLogDuration(fields["Init Duration"])
+	l.MemorySize = lambdaLogMegabytes(fields["Memory Size"])
+	l.MaxMemoryUsed = lambdaLogMegabytes(fields["Max Memory Used"])
+}
+
+// lambdaLogDuration parses a duration such as "1.50 ms", returning zero if there isn't one
+func lambdaLogDuration(value string) time.Duration {
+	milliseconds, err := strconv.ParseFloat(strings.TrimSuffix(value, " ms"), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(milliseconds * float64(time.Millisecond))
+}
+
+// lambdaLogMegabytes parses a size such as "128 MB", returning zero if there isn't one
+func lambdaLogMegabytes(value string) int {
+	megabytes, err := strconv.Atoi(strings.TrimSuffix(value, " MB"))
+	if err != nil {
+		return 0
+	}
+	return megabytes
+}
@@ -0,0 +1,79 @@
+package testcontainernetwork
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+const runtimeInterfaceEmulatorLog = `01 Jun 2024 12:00:00,000 [INFO] (rapid) exec '/var/runtime/bootstrap' (cwd=/var/task, handler=)
+01 Jun 2024 12:00:01,000 [INFO] (rapid) INIT START(type: on-demand, phase: init)
+START RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000001 Version: $LATEST
+2024/06/01 12:00:01 processing order 42
+2024/06/01 12:00:01 order 42 processed
+END RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000001
+REPORT RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000001	Init Duration: 1.52 ms	Duration: 12.25 ms	Billed Duration: 13 ms	Memory Size: 128 MB	Max Memory Used: 24 MB
+01 Jun 2024 12:00:02,000 [INFO] (rapid) INVOKE START(requestId: 3f1c8e2a-1111-4d5e-9f00-000000000002)
+START RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000002 Version: 7
+2024/06/01 12:00:02 processing order 43
+01 Jun 2024 12:00:02,100 [WARNING] (rapid) Reset initiated: Timeout
+END RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000002
+REPORT RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000002	Duration: 3000.00 ms	Billed Duration: 3000 ms	Memory Size: 128 MB	Max Memory Used: 25 MB
+START RequestId: 3f1c8e2a-1111-4d5e-9f00-000000000003 Version: $LATEST
+2024/06/01 12:00:03 processing order 44
+`
+
+func TestParseLambdaLog_SplitsLogIntoInvocations(t *testing.T) {
+	invocations, err := parseLambdaLog(strings.NewReader(runtimeInterfaceEmulatorLog))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []LambdaInvocationLog{
+		{
+			RequestId:      "3f1c8e2a-1111-4d5e-9f00-000000000001",
+			Version:        "$LATEST",
+			Lines:          []string{"2024/06/01 12:00:01 processing order 42", "2024/06/01 12:00:01 order 42 processed"},
+			Duration:       12250 * time.Microsecond,
+			BilledDuration: 13 * time.Millisecond,
+			InitDuration:   1520 * time.Microsecond,
+			MemorySize:     128,
+			MaxMemoryUsed:  24,
+			Reported:       true,
+		},
+		{
+			RequestId:      "3f1c8e2a-1111-4d5e-9f00-000000000002",
+			Version:        "7",
+			Lines:          []string{"2024/06/01 12:00:02 processing order 43"},
+			Duration:       3 * time.Second,
+			BilledDuration: 3 * time.Second,
+			MemorySize:     128,
+			MaxMemoryUsed:  25,
+			Reported:       true,
+		},
+		{
+			RequestId: "3f1c8e2a-1111-4d5e-9f00-000000000003",
+			Version:   "$LATEST",
+			Lines:     []string{"2024/06/01 12:00:03 processing order 44"},
+		},
+	}, invocations)
+	assert.Equal(t, "2024/06/01 12:00:01 processing order 42\n2024/06/01 12:00:01 order 42 processed", invocations[0].Output())
+}
+
+func TestLambdaDockerContainer_FindsInvocationsInLog(t *testing.T) {
+	provider := &FakeContainerProvider{Logs: map[string]string{"lambda": runtimeInterfaceEmulatorLog}}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main"}}
+	network := fakeNetwork(provider, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+
+	invocation, err := lambdaContainer.Invocation("3f1c8e2a-1111-4d5e-9f00-000000000002")
+	assert.NoError(t, err)
+	assert.Equal(t, "7", invocation.Version)
+
+	last, err := lambdaContainer.LastInvocation()
+	assert.NoError(t, err)
+	assert.Equal(t, "3f1c8e2a-1111-4d5e-9f00-000000000003", last.RequestId)
+	assert.False(t, last.Reported)
+
+	_, err = lambdaContainer.Invocation("unknown")
+	assert.EqualError(t, err, "no invocation with request ID unknown in the Lambda log")
+}
@@ -0,0 +1,138 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// lambdaWatchInterval is how often a watched Lambda checks whether its Executable or Package has changed
+const lambdaWatchInterval = 500 * time.Millisecond
+
+// moveReloadedExecutableScript moves the new executable, copied alongside the old one given as its argument, into its
+// place, which lambci/lambda watches for in order to restart the function; replaceLambdaFunctionScript stops the
+// function as stopLambdaFunctionScript does first, so that the Runtime Interface Emulator starts the new executable
+// for the next invocation
+const (
+	moveReloadedExecutableScript = `mv -f "$1.reload" "$1"`
+	replaceLambdaFunctionScript  = stopLambdaFunctionScript + "\n" + moveReloadedExecutableScript
+)
+
+// Reload swaps the function's executable for the current Executable, or a new build of its Package, and restarts the
+// function in each of its Instances, leaving the containers, and the rest of the network, running.  The function is
+// sent SIGTERM, as when the container stops, and the new executable is started by the next invocation.  Every instance
+// is reloaded even if some fail, and the error names those still running the old executable
+func (c *LambdaDockerContainer) Reload(ctx context.Context) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	if c.testContainer == nil {
+		return fmt.Errorf("reloading Lambda %s: it hasn't been started", c.FunctionName())
+	}
+	if c.Config.Executable == "" && c.Config.Package == "" {
+		return fmt.Errorf("reloading Lambda %s: only an Executable or Package can be reloaded", c.FunctionName())
+	}
+
+	started := time.Now()
+	err := c.reloadInstances(ctx)
+	c.emit(Event{Type: EventLambdaReloaded, Container: c.name, Image: c.Image(), Duration: time.Since(started), Err: err})
+	if err != nil {
+		return fmt.Errorf("reloading Lambda %s: %w", c.FunctionName(), err)
+	}
+	return nil
+}
+
+// reloadInstances replaces the executable in every instance, carrying on past those that fail so that as few as
+// possible are left running the old executable, and names those that are in its error
+func (c *LambdaDockerContainer) reloadInstances(ctx context.Context) error {
+	executable := c.Config.Executable
+	if c.Config.Package != "" {
+		var err error
+		if executable, err = c.lambdaBuild().build(ctx); err != nil {
+			return err
+		}
+	}
+	var errs []error
+	var stale []string
+	for _, instance := range append([]*LambdaDockerContainer{c}, c.instances...) {
+		if err := instance.replaceExecutable(ctx, executable); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", instance.Config.Hostname, err))
+			stale = append(stale, instance.Config.Hostname)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s still running the old executable: %w", strings.Join(stale, ", "), errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c *LambdaDockerContainer) replaceExecutable(ctx context.Context, executable string) error {
+	if err := c.testContainer.CopyFileToContainer(ctx, executable, c.containerExecutable()+".reload", executableFileMode); err != nil {
+		return fmt.Errorf("copying binary to docker container: %w", err)
+	}
+	script := replaceLambdaFunctionScript
+	if c.Config.Runtime == LambdaRuntimeGo1x {
+		script = moveReloadedExecutableScript
+	}
+	if err := c.exec(ctx, "sh", "-c", script, "sh", c.containerExecutable()); err != nil {
+		return fmt.Errorf("replacing Lambda function: %w", err)
+	}
+	c.executable = executable
+	c.invoked.Store(false)
+	return nil
+}
+
+// watch reloads the function whenever its Executable or Package changes, once it has stayed the same for a poll, so
+// that an executable being written, or a package being saved file by file, isn't loaded half done
+func (c *LambdaDockerContainer) watch() error {
+	loaded, err := c.sourceFingerprint()
+	if err != nil {
+		return fmt.Errorf("watching Lambda %s: %w", c.FunctionName(), err)
+	}
+	seen := loaded
+	c.watcher = &eventSourcePoller{pause: lambdaWatchInterval}
+	c.watcher.start(func(ctx context.Context) (int, error) {
+		current, err := c.sourceFingerprint()
+		if err != nil {
+			return 0, fmt.Errorf("watching Lambda %s: %w", c.FunctionName(), err)
+		}
+		if current != seen {
+			seen = current
+			return 0, nil
+		}
+		if current == loaded {
+			return 0, nil
+		}
+		loaded = current
+		return 0, c.Reload(ctx)
+	})
+	return nil
+}
+
+// sourceFingerprint changes whenever the Executable or the source of the Package does
+func (c *LambdaDockerContainer) sourceFingerprint() (string, error) {
+	if c.Config.Package != "" {
+		return c.lambdaBuild().sourceHash()
+	}
+	info, err := os.Stat(c.Config.Executable)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// ReloadErrors returns the errors met reloading a watched Lambda, such as its Package failing to compile
+func (c *LambdaDockerContainer) ReloadErrors() []error {
+	if c.watcher == nil {
+		return nil
+	}
+	return c.watcher.errors()
+}
+
+func (c *LambdaDockerContainer) stopWatching() {
+	if c.watcher != nil {
+		c.watcher.stop()
+	}
+}
@@ -0,0 +1,90 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLambdaDockerContainer_ReloadsExecutableWithoutRestartingContainer(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main"}}
+	var events []Event
+	network := fakeNetwork(provider, lambdaContainer).WithEventListener(EventListenerFunc(func(event Event) {
+		events = append(events, event)
+	}))
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.NoError(t, lambdaContainer.Reload(context.Background()))
+
+	calls := callsAsStrings(provider.Calls())
+	assert.Contains(t, calls, "copy to container lambda main -> /var/runtime/bootstrap.reload (755)")
+	assert.Contains(t, calls, "exec in container lambda sh -c "+replaceLambdaFunctionScript+" sh /var/runtime/bootstrap")
+	assert.NotContains(t, calls, "stop container lambda")
+	assert.Equal(t, EventLambdaReloaded, events[len(events)-1].Type)
+	assert.Equal(t, "lambda", events[len(events)-1].Container)
+}
+
+func TestLambdaDockerContainer_ReloadsLegacyGo1xRuntimeByReplacingHandler(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", Runtime: LambdaRuntimeGo1x}}
+	network := fakeNetwork(provider, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.NoError(t, lambdaContainer.Reload(context.Background()))
+
+	req, _ := provider.Request("lambda")
+	assert.Equal(t, "1", req.Env["DOCKER_LAMBDA_WATCH"])
+	assert.Contains(t, callsAsStrings(provider.Calls()), `exec in container lambda sh -c mv -f "$1.reload" "$1" sh /var/task/handler`)
+}
+
+func TestLambdaDockerContainer_ReloadsWatchedExecutableWhenItChanges(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "bootstrap")
+	assert.NoError(t, os.WriteFile(executable, []byte("version 1"), 0o755))
+	provider := &FakeContainerProvider{}
+	network := fakeNetwork(provider, &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: executable, Watch: true}})
+	assert.NoError(t, network.StartWithDelay(0))
+	defer network.Stop()
+
+	assert.NoError(t, os.WriteFile(executable, []byte("version 2 of the function"), 0o755))
+
+	assert.Eventually(t, func() bool {
+		for _, call := range callsAsStrings(provider.Calls()) {
+			if call == "copy to container lambda "+executable+" -> /var/runtime/bootstrap.reload (755)" {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestLambdaDockerContainer_RecordsErrorsReloadingWatchedPackage(t *testing.T) {
+	withEmptyLambdaBuildCache(t)
+	pkg := lambdaPackage(t, "package main\n\nfunc main() {}\n")
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Package: pkg, Watch: true}}
+	network := fakeNetwork(&FakeContainerProvider{}, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+	defer network.Stop()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pkg, "main.go"), []byte("package main\n\nfunc main() {\n\tundefined()\n}\n"), 0o644))
+
+	assert.Eventually(t, func() bool {
+		return len(lambdaContainer.ReloadErrors()) > 0
+	}, 30*time.Second, 50*time.Millisecond)
+	assert.ErrorContains(t, lambdaContainer.ReloadErrors()[0], "reloading Lambda function: building Lambda package")
+	assert.ErrorContains(t, lambdaContainer.ReloadErrors()[0], "undefined: undefined")
+}
+
+func TestLambdaDockerContainer_OnlyReloadsExecutableOrPackage(t *testing.T) {
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Image: "example.com/orders:ci"}}
+	network := fakeNetwork(&FakeContainerProvider{}, lambdaContainer)
+	assert.NoError(t, network.StartWithDelay(0))
+
+	assert.EqualError(t, lambdaContainer.Reload(context.Background()), "reloading Lambda function: only an Executable or Package can be reloaded")
+
+	lambdaContainer.Config.Watch = true
+	assert.EqualError(t, lambdaContainer.StartUsing(context.Background(), nil), "only a Lambda deployed from an Executable or Package can be watched")
+}
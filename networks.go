@@ -0,0 +1,96 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// DefaultNetwork is the name of the docker network that containers join unless they are told to join others
+const DefaultNetwork = "default"
+
+// WithSubNetwork adds a named docker network to the network, such as the private subnet of a VPC, which containers
+// can join with OnNetwork
+func (n NetworkOfDockerContainers) WithSubNetwork(name string) NetworkOfDockerContainers {
+	n.subNetworkNames = append(n.subNetworkNames, name)
+	return n
+}
+
+// OnNetwork makes the container join the named network, which is either DefaultNetwork or one added to the network
+// with WithSubNetwork, with the given aliases in addition to its hostname.  A container that is on no networks joins
+// DefaultNetwork, and one that is on any is only on those it is told to join
+func (c *DockerContainer) OnNetwork(name string, aliases ...string) {
+	c.memberships = append(c.memberships, networkMembership{name: name, aliases: aliases})
+}
+
+type networkMembership struct {
+	name    string
+	aliases []string
+}
+
+func (n *NetworkOfDockerContainers) createSubNetworks(ctx context.Context) error {
+	n.subNetworks = map[string]*testcontainers.DockerNetwork{}
+	for _, name := range n.subNetworkNames {
+		if name == DefaultNetwork || n.subNetworks[name] != nil {
+			return fmt.Errorf("network %s already exists", name)
+		}
+		dockerNetwork, err := n.containerProvider().CreateNetwork(ctx)
+		if err != nil {
+			return fmt.Errorf("creating network %s: %s", name, err)
+		}
+		n.subNetworks[name] = dockerNetwork
+	}
+	return nil
+}
+
+func (n *NetworkOfDockerContainers) removeSubNetworks(ctx context.Context) error {
+	for _, name := range n.subNetworkNames {
+		dockerNetwork, ok := n.subNetworks[name]
+		if !ok {
+			continue
+		}
+		if err := n.containerProvider().RemoveNetwork(ctx, dockerNetwork); err != nil {
+			return fmt.Errorf("removing network %s: %v", name, err)
+		}
+		delete(n.subNetworks, name)
+	}
+	return nil
+}
+
+func (n *NetworkOfDockerContainers) dockerNetworkNamed(name string) (*testcontainers.DockerNetwork, error) {
+	if name == DefaultNetwork {
+		return n.dockerNetwork, nil
+	}
+	if dockerNetwork, ok := n.subNetworks[name]; ok {
+		return dockerNetwork, nil
+	}
+	return nil, fmt.Errorf("unknown network %s", name)
+}
+
+// joinNetworks attaches the container described by req to the networks it has been told to join, rather than the
+// default network that StartUsing attached it to
+func (c *DockerContainer) joinNetworks(req *testcontainers.ContainerRequest) error {
+	if len(c.memberships) == 0 || c.network == nil {
+		return nil
+	}
+	req.Networks = nil
+	req.NetworkAliases = map[string][]string{}
+	for _, membership := range c.memberships {
+		dockerNetwork, err := c.network.dockerNetworkNamed(membership.name)
+		if err != nil {
+			return err
+		}
+		req.Networks = append(req.Networks, dockerNetwork.Name)
+		req.NetworkAliases[dockerNetwork.Name] = append([]string{req.Hostname}, membership.aliases...)
+	}
+	primaryNetwork := container.NetworkMode(req.Networks[0])
+	hostConfigModifier := req.HostConfigModifier
+	req.HostConfigModifier = func(config *container.HostConfig) {
+		if hostConfigModifier != nil {
+			hostConfigModifier(config)
+		}
+		config.NetworkMode = primaryNetwork
+	}
+	return nil
+}
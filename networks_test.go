@@ -0,0 +1,52 @@
+package testcontainernetwork
+
+import (
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNetworkOfDockerContainers_AttachesContainersToSubNetworks(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	proxyContainer := &WiremockDockerContainer{Config: WiremockDockerContainerConfig{Hostname: "proxy", Port: 8080}}
+	proxyContainer.OnNetwork("public", "api.example.com")
+	proxyContainer.OnNetwork("private")
+	postgresContainer := &PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "aurora", Port: 5432}}
+	postgresContainer.OnNetwork("private")
+	network := fakeNetwork(provider,
+		proxyContainer,
+		postgresContainer,
+		&SqsDockerContainer{Config: SqsDockerContainerConfig{Hostname: "sqs", Port: 9324}},
+	).WithSubNetwork("public").WithSubNetwork("private")
+
+	assert.NoError(t, network.StartWithDelay(0))
+	assert.NoError(t, network.Stop())
+
+	proxyReq, _ := provider.Request("proxy")
+	assert.Equal(t, []string{"fake-network-2", "fake-network-3"}, proxyReq.Networks)
+	assert.Equal(t, map[string][]string{"fake-network-2": {"proxy", "api.example.com"}, "fake-network-3": {"proxy"}}, proxyReq.NetworkAliases)
+	postgresReq, _ := provider.Request("aurora")
+	assert.Equal(t, []string{"fake-network-3"}, postgresReq.Networks)
+	hostConfig := &container.HostConfig{}
+	postgresReq.HostConfigModifier(hostConfig)
+	assert.Equal(t, container.NetworkMode("fake-network-3"), hostConfig.NetworkMode)
+	sqsReq, _ := provider.Request("sqs")
+	assert.Equal(t, []string{"fake-network-1"}, sqsReq.Networks)
+	calls := callsAsStrings(provider.Calls())
+	assert.Equal(t, []string{
+		"remove network fake-network-2",
+		"remove network fake-network-3",
+		"remove network fake-network-1",
+	}, calls[len(calls)-3:])
+}
+
+func TestNetworkOfDockerContainers_FailsWhenContainerJoinsUnknownNetwork(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	postgresContainer := &PostgresDockerContainer{Config: PostgresDockerContainerConfig{Hostname: "aurora", Port: 5432}}
+	postgresContainer.OnNetwork("private")
+	network := fakeNetwork(provider, postgresContainer)
+
+	err := network.StartWithDelay(0)
+
+	assert.ErrorContains(t, err, "unknown network private")
+}
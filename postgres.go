@@ -7,6 +7,8 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 )
 
+const postgresImage = "postgres:13"
+
 type PostgresDockerContainerConfig struct {
 	Hostname    string
 	Port        int
@@ -18,10 +20,14 @@ type PostgresDockerContainer struct {
 	Config PostgresDockerContainerConfig
 }
 
+func (c *PostgresDockerContainer) Image() string {
+	return postgresImage
+}
+
 func (c *PostgresDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 	req := testcontainers.ContainerRequest{
-		Image:        "postgres:13",
+		Image:        c.Image(),
 		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
 		Name:         c.Config.Hostname,
 		Hostname:     c.Config.Hostname,
@@ -33,16 +39,37 @@ func (c *PostgresDockerContainer) StartUsing(ctx context.Context, dockerNetwork
 		Env: c.Config.Environment,
 	}
 
-	var err error
-	if c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	}); err != nil {
-		return fmt.Errorf("creating container: %w", err)
+	return c.createAndStart(ctx, req)
+}
+
+const postgresSnapshotFile = "/tmp/snapshot.dump"
+
+// Snapshot dumps the database to a file inside the container
+func (c *PostgresDockerContainer) Snapshot(ctx context.Context) error {
+	if err := c.exec(ctx, "pg_dump", "--username="+c.user(), "--dbname="+c.database(), "--format=custom", "--file="+postgresSnapshotFile); err != nil {
+		return fmt.Errorf("dumping database: %w", err)
 	}
+	return nil
+}
 
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+// Restore drops the objects in the snapshot, along with any data written to them, and recreates them from the snapshot
+func (c *PostgresDockerContainer) Restore(ctx context.Context) error {
+	if err := c.exec(ctx, "pg_restore", "--username="+c.user(), "--dbname="+c.database(), "--clean", "--if-exists", postgresSnapshotFile); err != nil {
+		return fmt.Errorf("restoring database: %w", err)
 	}
 	return nil
 }
+
+func (c *PostgresDockerContainer) user() string {
+	if user, ok := c.Config.Environment["POSTGRES_USER"]; ok {
+		return user
+	}
+	return "postgres"
+}
+
+func (c *PostgresDockerContainer) database() string {
+	if database, ok := c.Config.Environment["POSTGRES_DB"]; ok {
+		return database
+	}
+	return c.user()
+}
@@ -0,0 +1,170 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/network"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ContainerProvider creates the docker networks and containers that the network and its containers are built from,
+// and manages their images.  By default the network uses Docker via testcontainers, but it can be given a
+// FakeContainerProvider to test code that sets up a network without a Docker daemon
+type ContainerProvider interface {
+	CreateNetwork(ctx context.Context) (*testcontainers.DockerNetwork, error)
+	RemoveNetwork(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error
+	CreateContainer(ctx context.Context, req testcontainers.ContainerRequest) (Container, error)
+	ImageExists(ctx context.Context, image string) (bool, error)
+	PullImage(ctx context.Context, image string, progress io.Writer) error
+	LoadImage(ctx context.Context, archive string) error
+	HostRuntime(ctx context.Context) (HostRuntime, error)
+}
+
+// Container is the part of testcontainers.Container that the library uses
+type Container interface {
+	Start(ctx context.Context) error
+	Terminate(ctx context.Context) error
+	MappedPort(ctx context.Context, port nat.Port) (nat.Port, error)
+	Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error)
+	Logs(ctx context.Context) (io.ReadCloser, error)
+	CopyFileToContainer(ctx context.Context, hostFilePath string, containerFilePath string, fileMode int64) error
+	CopyToContainer(ctx context.Context, fileContent []byte, containerFilePath string, fileMode int64) error
+	CopyFileFromContainer(ctx context.Context, filePath string) (io.ReadCloser, error)
+}
+
+func (n NetworkOfDockerContainers) WithProvider(provider ContainerProvider) NetworkOfDockerContainers {
+	n.provider = provider
+	return n
+}
+
+func (n *NetworkOfDockerContainers) containerProvider() ContainerProvider {
+	if n.provider == nil {
+		n.provider = &dockerProvider{}
+	}
+	return n.provider
+}
+
+// dockerProvider is the default ContainerProvider, which uses Docker via testcontainers
+type dockerProvider struct {
+	once   sync.Once
+	client *testcontainers.DockerClient
+	err    error
+}
+
+func (p *dockerProvider) dockerClient(ctx context.Context) (*testcontainers.DockerClient, error) {
+	p.once.Do(func() {
+		if p.client, p.err = testcontainers.NewDockerClientWithOpts(ctx); p.err != nil {
+			p.err = fmt.Errorf("creating docker client: %w", p.err)
+		}
+	})
+	return p.client, p.err
+}
+
+// Close closes the Docker client, if the provider has created one, and lets it create another should it be used again
+func (p *dockerProvider) Close() error {
+	if p.client == nil {
+		return nil
+	}
+	err := p.client.Close()
+	p.once, p.client, p.err = sync.Once{}, nil, nil
+	if err != nil {
+		return fmt.Errorf("closing docker client: %w", err)
+	}
+	return nil
+}
+
+func (p *dockerProvider) CreateNetwork(ctx context.Context) (*testcontainers.DockerNetwork, error) {
+	return network.New(ctx)
+}
+
+func (p *dockerProvider) RemoveNetwork(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
+	return dockerNetwork.Remove(ctx)
+}
+
+func (p *dockerProvider) CreateContainer(ctx context.Context, req testcontainers.ContainerRequest) (Container, error) {
+	return testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          false,
+	})
+}
+
+func (p *dockerProvider) ImageExists(ctx context.Context, img string) (bool, error) {
+	dockerClient, err := p.dockerClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, _, err := dockerClient.ImageInspectWithRaw(ctx, img); err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("inspecting image %s: %w", img, err)
+	}
+	return true, nil
+}
+
+func (p *dockerProvider) PullImage(ctx context.Context, img string, progress io.Writer) error {
+	dockerClient, err := p.dockerClient(ctx)
+	if err != nil {
+		return err
+	}
+	pull, err := dockerClient.ImagePull(ctx, img, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling image %s: %w", img, err)
+	}
+	defer pull.Close()
+
+	layers := map[string]*jsonmessage.JSONProgress{}
+	lastReported := time.Now()
+	decoder := json.NewDecoder(pull)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("reading pull progress for %s: %w", img, err)
+		}
+		if message.Error != nil {
+			return fmt.Errorf("pulling image %s: %s", img, message.Error.Message)
+		}
+		if message.Status == "Downloading" && message.Progress != nil {
+			layers[message.ID] = message.Progress
+		}
+		if time.Since(lastReported) >= pullProgressInterval && len(layers) > 0 {
+			fmt.Fprintf(progress, "%s: downloading %s\n", img, downloadProgress(layers))
+			lastReported = time.Now()
+		}
+	}
+}
+
+func (p *dockerProvider) LoadImage(ctx context.Context, archive string) error {
+	dockerClient, err := p.dockerClient(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("opening image archive: %w", err)
+	}
+	defer f.Close()
+
+	response, err := dockerClient.ImageLoad(ctx, f, true)
+	if err != nil {
+		return fmt.Errorf("loading image archive %s: %w", archive, err)
+	}
+	defer response.Body.Close()
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return fmt.Errorf("reading response loading image archive %s: %w", archive, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResettableDockerContainer is implemented by containers whose state can be snapshotted and later restored, so that
+// data written in one scenario doesn't leak into the next
+type ResettableDockerContainer interface {
+	Snapshot(ctx context.Context) error
+	Restore(ctx context.Context) error
+}
+
+// Snapshot records the state of each container in the network that supports it, typically once the network has started
+func (n *NetworkOfDockerContainers) Snapshot() error {
+	ctx := context.Background()
+	for _, dockerContainer := range n.dockerContainers {
+		if resettable, ok := dockerContainer.(ResettableDockerContainer); ok {
+			if err := resettable.Snapshot(ctx); err != nil {
+				return fmt.Errorf("snapshotting docker container: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Restore returns each container in the network that supports it to the state recorded by Snapshot, typically before
+// each scenario
+func (n *NetworkOfDockerContainers) Restore() error {
+	ctx := context.Background()
+	for _, dockerContainer := range n.dockerContainers {
+		if resettable, ok := dockerContainer.(ResettableDockerContainer); ok {
+			if err := resettable.Restore(ctx); err != nil {
+				return fmt.Errorf("restoring docker container: %v", err)
+			}
+		}
+	}
+	return nil
+}
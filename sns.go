@@ -9,21 +9,31 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 )
 
+const snsImage = "warrenseine/sns"
+
 type SnsDockerContainerConfig struct {
 	Hostname   string
 	Port       int
 	ConfigFile string
+	// Topics and Subscriptions are created in addition to any in ConfigFile
+	Topics        []string
+	Subscriptions []SnsSubscription
 }
 
 type SnsDockerContainer struct {
 	DockerContainer
 	Config SnsDockerContainerConfig
+	relay  *snsLambdaRelay
+}
+
+func (c *SnsDockerContainer) Image() string {
+	return snsImage
 }
 
 func (c *SnsDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 	req := testcontainers.ContainerRequest{
-		Image:        "warrenseine/sns",
+		Image:        c.Image(),
 		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
 		Name:         c.Config.Hostname,
 		Hostname:     c.Config.Hostname,
@@ -33,22 +43,77 @@ func (c *SnsDockerContainer) StartUsing(ctx context.Context, dockerNetwork *test
 			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
 		},
 	}
-	var err error
-	if c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	}); err != nil {
-		return fmt.Errorf("creating container: %w", err)
+	if len(c.Config.Topics) == 0 && len(c.Config.Subscriptions) == 0 {
+		return c.createAndStart(ctx, req, func(ctx context.Context) error {
+			if err := c.testContainer.CopyFileToContainer(ctx, c.Config.ConfigFile, "/etc/sns/db.json", configFileMode); err != nil {
+				return fmt.Errorf("copying config file to docker container: %w", err)
+			}
+			return nil
+		})
 	}
 
-	if err = c.testContainer.CopyFileToContainer(ctx, c.Config.ConfigFile, "/etc/sns/db.json", 365); err != nil {
-		return fmt.Errorf("copying config file to docker container: %v", err)
+	lambdas := map[string]func(ctx context.Context, event any) (LambdaResponse, error){}
+	for _, subscription := range c.Config.Subscriptions {
+		if subscription.Lambda != nil {
+			lambdas[subscription.Lambda.FunctionName()] = subscription.Lambda.Invoke
+		}
 	}
+	var relayPort int
+	c.relay = nil
+	if len(lambdas) > 0 {
+		relay, err := startSnsLambdaRelay(lambdas)
+		if err != nil {
+			return err
+		}
+		c.relay = relay
+		relayPort = relay.port()
+		req.HostAccessPorts = append(req.HostAccessPorts, relayPort)
+	}
+	err := c.startWithDb(ctx, req, relayPort)
+	if err != nil && c.relay != nil {
+		_ = c.relay.stop(ctx)
+		c.relay = nil
+	}
+	return err
+}
 
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+// startWithDb starts the container with the configuration of its topics and subscriptions, delivering to Lambdas
+// through the relay listening on relayPort
+func (c *SnsDockerContainer) startWithDb(ctx context.Context, req testcontainers.ContainerRequest, relayPort int) error {
+	db, err := c.db(relayPort)
+	if err != nil {
+		return err
 	}
-	return nil
+	dbJson, err := json.Marshal(db)
+	if err != nil {
+		return fmt.Errorf("marshalling config: %w", err)
+	}
+	return c.createAndStart(ctx, req, func(ctx context.Context) error {
+		if err := c.testContainer.CopyToContainer(ctx, dbJson, "/etc/sns/db.json", configFileMode); err != nil {
+			return fmt.Errorf("copying config to docker container: %w", err)
+		}
+		return nil
+	})
+}
+
+// Stop stops the container and the relay that delivers notifications to Lambdas
+func (c *SnsDockerContainer) Stop(ctx context.Context) error {
+	if c.relay != nil {
+		if err := c.relay.stop(ctx); err != nil {
+			return err
+		}
+		c.relay = nil
+	}
+	return c.DockerContainer.Stop(ctx)
+}
+
+// DeliveryErrors returns the errors met delivering notifications to Lambdas, such as the Lambda returning a function
+// error
+func (c *SnsDockerContainer) DeliveryErrors() []error {
+	if c.relay == nil {
+		return nil
+	}
+	return c.relay.errors()
 }
 
 func (c *SnsDockerContainer) GetMessage() (string, error) {
@@ -78,3 +143,16 @@ func (c *SnsDockerContainer) GetMessage() (string, error) {
 
 	return snsMessage.Message, nil
 }
+
+// Snapshot does nothing as the topics are restored by clearing the log of published messages
+func (c *SnsDockerContainer) Snapshot(context.Context) error {
+	return nil
+}
+
+// Restore clears the log of published messages
+func (c *SnsDockerContainer) Restore(ctx context.Context) error {
+	if err := c.exec(ctx, "rm", "-f", "/tmp/sns.log"); err != nil {
+		return fmt.Errorf("clearing log file: %w", err)
+	}
+	return nil
+}
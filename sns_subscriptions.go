@@ -0,0 +1,203 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SnsSubscription subscribes to a topic either a queue on an SqsDockerContainer, which receives the notifications that
+// are published to the topic, or the messages themselves with RawMessageDelivery, or a LambdaDockerContainer, which is
+// invoked with an events.SNSEvent
+type SnsSubscription struct {
+	Topic              string
+	Sqs                *SqsDockerContainer
+	Queue              string
+	RawMessageDelivery bool
+	Lambda             *LambdaDockerContainer
+}
+
+// snsDb is the configuration of the topics and subscriptions of the SNS container, which it reads from /etc/sns/db.json
+type snsDb struct {
+	Version       int                 `json:"version"`
+	Timestamp     int64               `json:"timestamp"`
+	Subscriptions []snsDbSubscription `json:"subscriptions"`
+	Topics        []snsDbTopic        `json:"topics"`
+}
+
+type snsDbSubscription struct {
+	Arn      string `json:"arn"`
+	TopicArn string `json:"topicArn"`
+	Endpoint string `json:"endpoint"`
+	Owner    string `json:"owner"`
+	Protocol string `json:"protocol"`
+	Raw      bool   `json:"raw,omitempty"`
+}
+
+type snsDbTopic struct {
+	Arn  string `json:"arn"`
+	Name string `json:"name"`
+}
+
+// TopicArn is the ARN of the topic with the given name that the container creates for its Topics and Subscriptions,
+// which is in the Region of the first Lambda subscribed to it, as a Lambda is invoked by topics in its own region
+func (c *SnsDockerContainer) TopicArn(topic string) string {
+	var region string
+	for _, subscription := range c.Config.Subscriptions {
+		if subscription.Topic == topic && subscription.Lambda != nil {
+			region = subscription.Lambda.Config.Region
+			break
+		}
+	}
+	return fmt.Sprintf("arn:aws:sns:%s:%s:%s", regionOrDefault(region), eventAccountId, topic)
+}
+
+// db returns the configuration of the topics and subscriptions in ConfigFile, if there is one, along with those in
+// Topics and Subscriptions, delivering to Lambdas through the relay listening on relayPort
+func (c *SnsDockerContainer) db(relayPort int) (snsDb, error) {
+	db := snsDb{Version: 1, Timestamp: time.Now().Unix()}
+	if c.Config.ConfigFile != "" {
+		content, err := os.ReadFile(c.Config.ConfigFile)
+		if err != nil {
+			return snsDb{}, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := json.Unmarshal(content, &db); err != nil {
+			return snsDb{}, fmt.Errorf("unmarshalling config file: %w", err)
+		}
+	}
+
+	addTopic := func(topic string) {
+		for _, existing := range db.Topics {
+			if existing.Arn == c.TopicArn(topic) {
+				return
+			}
+		}
+		db.Topics = append(db.Topics, snsDbTopic{Arn: c.TopicArn(topic), Name: topic})
+	}
+	for _, topic := range c.Config.Topics {
+		addTopic(topic)
+	}
+	for i, subscription := range c.Config.Subscriptions {
+		addTopic(subscription.Topic)
+		dbSubscription := snsDbSubscription{
+			Arn:      fmt.Sprintf("%s:%08d-0000-0000-0000-000000000000", c.TopicArn(subscription.Topic), i+1),
+			TopicArn: c.TopicArn(subscription.Topic),
+		}
+		switch {
+		case subscription.Sqs != nil && subscription.Lambda == nil:
+			dbSubscription.Protocol = "sqs"
+			dbSubscription.Endpoint = fmt.Sprintf("aws-sqs://%s?amazonSQSEndpoint=http://%s:%d&accessKey=x&secretKey=x",
+				subscription.Queue, subscription.Sqs.Config.Hostname, subscription.Sqs.Config.Port)
+			dbSubscription.Raw = subscription.RawMessageDelivery
+		case subscription.Lambda != nil && subscription.Sqs == nil:
+			dbSubscription.Protocol = "http"
+			dbSubscription.Endpoint = fmt.Sprintf("http://%s:%d/%s", HostInternal, relayPort, subscription.Lambda.FunctionName())
+		default:
+			return snsDb{}, fmt.Errorf("subscription to %s must be to either a queue or a Lambda", subscription.Topic)
+		}
+		db.Subscriptions = append(db.Subscriptions, dbSubscription)
+	}
+	return db, nil
+}
+
+// snsLambdaRelay receives the notifications that the SNS container delivers to HTTP subscriptions and invokes the
+// Lambdas with them as AWS does, since the container can only deliver them as they are
+type snsLambdaRelay struct {
+	listener net.Listener
+	server   *http.Server
+	lambdas  map[string]func(ctx context.Context, event any) (LambdaResponse, error)
+	mu       sync.Mutex
+	errs     []error
+}
+
+func startSnsLambdaRelay(lambdas map[string]func(ctx context.Context, event any) (LambdaResponse, error)) (*snsLambdaRelay, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listening for SNS notifications: %w", err)
+	}
+	relay := &snsLambdaRelay{listener: listener, lambdas: lambdas}
+	relay.server = &http.Server{Handler: relay}
+	go func() {
+		_ = relay.server.Serve(listener)
+	}()
+	return relay, nil
+}
+
+func (r *snsLambdaRelay) port() int {
+	return r.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (r *snsLambdaRelay) stop(ctx context.Context) error {
+	if err := r.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("stopping SNS relay: %w", err)
+	}
+	return nil
+}
+
+func (r *snsLambdaRelay) failed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *snsLambdaRelay) errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error{}, r.errs...)
+}
+
+func (r *snsLambdaRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	functionName := strings.TrimPrefix(req.URL.Path, "/")
+	invoke, ok := r.lambdas[functionName]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.failed(fmt.Errorf("reading SNS notification: %w", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	event, err := snsEventFrom(body)
+	if err != nil {
+		r.failed(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if _, err := invoke(req.Context(), event); err != nil {
+		r.failed(fmt.Errorf("invoking Lambda %s with SNS notification: %w", functionName, err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// snsEventFrom converts a notification, as delivered to HTTP subscriptions, into the event that a Lambda is invoked with
+func snsEventFrom(notification []byte) (events.SNSEvent, error) {
+	var entity events.SNSEntity
+	if err := json.Unmarshal(notification, &entity); err != nil {
+		return events.SNSEvent{}, fmt.Errorf("unmarshalling SNS notification: %w", err)
+	}
+	topic := entity.TopicArn[strings.LastIndex(entity.TopicArn, ":")+1:]
+	event := NewSnsEvent(topic).WithMessageAttributes(entity.Subject, entity.Message, entity.MessageAttributes).Build()
+	record := &event.Records[0]
+	record.SNS.TopicArn = entity.TopicArn
+	record.EventSubscriptionArn = entity.TopicArn + ":subscription"
+	if entity.MessageID != "" {
+		record.SNS.MessageID = entity.MessageID
+	}
+	if !entity.Timestamp.IsZero() {
+		record.SNS.Timestamp = entity.Timestamp
+	}
+	return event, nil
+}
@@ -0,0 +1,176 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSnsDockerContainer_DbMergesConfigFileWithTopicsAndSubscriptions(t *testing.T) {
+	snsContainer := SnsDockerContainer{Config: SnsDockerContainerConfig{
+		ConfigFile: "test-assets/sns/sns.json",
+		Topics:     []string{"orders", "sns-topic"},
+		Subscriptions: []SnsSubscription{
+			{Topic: "orders", Sqs: &SqsDockerContainer{Config: SqsDockerContainerConfig{Hostname: "sqs", Port: 9324}}, Queue: "orders-queue", RawMessageDelivery: true},
+			{Topic: "refunds", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "refunder"}}},
+		},
+	}}
+
+	db, err := snsContainer.db(45678)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []snsDbTopic{
+		{Arn: "arn:aws:sns:eu-west-1:12345678999:sns-topic", Name: "sns-topic"},
+		{Arn: "arn:aws:sns:eu-west-1:000000000000:orders", Name: "orders"},
+		{Arn: "arn:aws:sns:eu-west-1:000000000000:sns-topic", Name: "sns-topic"},
+		{Arn: "arn:aws:sns:eu-west-1:000000000000:refunds", Name: "refunds"},
+	}, db.Topics)
+	assert.Equal(t, []snsDbSubscription{
+		{
+			Arn:      "889aad80-be7d-11e9-9cb5-2a2ae2dbcce4",
+			TopicArn: "arn:aws:sns:eu-west-1:12345678999:sns-topic",
+			Endpoint: "file://tmp?fileName=sns.log",
+			Protocol: "file",
+		},
+		{
+			Arn:      "arn:aws:sns:eu-west-1:000000000000:orders:00000001-0000-0000-0000-000000000000",
+			TopicArn: "arn:aws:sns:eu-west-1:000000000000:orders",
+			Endpoint: "aws-sqs://orders-queue?amazonSQSEndpoint=http://sqs:9324&accessKey=x&secretKey=x",
+			Protocol: "sqs",
+			Raw:      true,
+		},
+		{
+			Arn:      "arn:aws:sns:eu-west-1:000000000000:refunds:00000002-0000-0000-0000-000000000000",
+			TopicArn: "arn:aws:sns:eu-west-1:000000000000:refunds",
+			Endpoint: "http://host.testcontainers.internal:45678/refunder",
+			Protocol: "http",
+		},
+	}, db.Subscriptions)
+}
+
+func TestSnsDockerContainer_TopicArnIsInRegionOfSubscribedLambda(t *testing.T) {
+	snsContainer := SnsDockerContainer{Config: SnsDockerContainerConfig{
+		Topics: []string{"audit"},
+		Subscriptions: []SnsSubscription{
+			{Topic: "refunds", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Region: "us-east-1"}}},
+		},
+	}}
+
+	assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:refunds", snsContainer.TopicArn("refunds"))
+	assert.Equal(t, "arn:aws:sns:eu-west-1:000000000000:audit", snsContainer.TopicArn("audit"))
+}
+
+func TestSnsDockerContainer_DbRejectsSubscriptionsToNeitherOrBothTargets(t *testing.T) {
+	for _, subscription := range []SnsSubscription{
+		{Topic: "orders"},
+		{Topic: "orders", Sqs: &SqsDockerContainer{}, Lambda: &LambdaDockerContainer{}},
+	} {
+		snsContainer := SnsDockerContainer{Config: SnsDockerContainerConfig{Subscriptions: []SnsSubscription{subscription}}}
+
+		_, err := snsContainer.db(0)
+
+		assert.EqualError(t, err, "subscription to orders must be to either a queue or a Lambda")
+	}
+}
+
+func TestSnsEventFrom_ConvertsHttpNotificationToEvent(t *testing.T) {
+	notification := `{
+		"Type": "Notification",
+		"MessageId": "1c9c2b4e-0000-0000-0000-000000000000",
+		"TopicArn": "arn:aws:sns:eu-west-1:000000000000:orders",
+		"Subject": "order placed",
+		"Message": "{\"id\":42}",
+		"Timestamp": "2024-06-01T12:00:00.000Z",
+		"MessageAttributes": {"source": {"Type": "String", "Value": "web"}}
+	}`
+
+	event, err := snsEventFrom([]byte(notification))
+
+	assert.NoError(t, err)
+	assert.Len(t, event.Records, 1)
+	record := event.Records[0]
+	assert.Equal(t, "aws:sns", record.EventSource)
+	assert.Equal(t, "arn:aws:sns:eu-west-1:000000000000:orders:subscription", record.EventSubscriptionArn)
+	assert.Equal(t, "1c9c2b4e-0000-0000-0000-000000000000", record.SNS.MessageID)
+	assert.Equal(t, "arn:aws:sns:eu-west-1:000000000000:orders", record.SNS.TopicArn)
+	assert.Equal(t, "order placed", record.SNS.Subject)
+	assert.Equal(t, `{"id":42}`, record.SNS.Message)
+	assert.Equal(t, 2024, record.SNS.Timestamp.Year())
+	assert.Equal(t, map[string]interface{}{"Type": "String", "Value": "web"}, record.SNS.MessageAttributes["source"])
+}
+
+func TestSnsLambdaRelay_InvokesLambdaNamedInPathAndRecordsFailures(t *testing.T) {
+	var invokedWith []events.SNSEvent
+	relay, err := startSnsLambdaRelay(map[string]func(ctx context.Context, event any) (LambdaResponse, error){
+		"refunder": func(_ context.Context, event any) (LambdaResponse, error) {
+			invokedWith = append(invokedWith, event.(events.SNSEvent))
+			if len(invokedWith) > 1 {
+				return LambdaResponse{}, errors.New("refund failed")
+			}
+			return LambdaResponse{StatusCode: http.StatusOK}, nil
+		},
+	})
+	assert.NoError(t, err)
+	defer relay.stop(context.Background())
+
+	post := func(functionName string) int {
+		res, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/%s", relay.port(), functionName), "text/plain",
+			strings.NewReader(`{"TopicArn": "arn:aws:sns:eu-west-1:000000000000:refunds", "Message": "refund 42"}`))
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, post("refunder"))
+	assert.Equal(t, http.StatusInternalServerError, post("refunder"))
+	assert.Equal(t, http.StatusNotFound, post("nobody"))
+	assert.Len(t, invokedWith, 2)
+	assert.Equal(t, "refund 42", invokedWith[0].Records[0].SNS.Message)
+	assert.EqualError(t, errors.Join(relay.errors()...), "invoking Lambda refunder with SNS notification: refund failed")
+}
+
+func TestNetworkOfDockerContainers_CopiesSnsSubscriptionsAndGivesAccessToRelay(t *testing.T) {
+	provider := &FakeContainerProvider{}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "refunder"}}
+	snsContainer := &SnsDockerContainer{Config: SnsDockerContainerConfig{
+		Hostname:      "sns",
+		Port:          9911,
+		Subscriptions: []SnsSubscription{{Topic: "refunds", Lambda: lambdaContainer}},
+	}}
+	network := fakeNetwork(provider, lambdaContainer, snsContainer)
+
+	assert.NoError(t, network.StartWithDelay(0))
+
+	req, _ := provider.Request("sns")
+	assert.Len(t, req.HostAccessPorts, 1)
+	assert.Equal(t, snsContainer.relay.port(), req.HostAccessPorts[0])
+	var copied bool
+	for _, call := range callsAsStrings(provider.Calls()) {
+		copied = copied || strings.HasSuffix(call, "-> /etc/sns/db.json (644)")
+	}
+	assert.True(t, copied)
+
+	assert.NoError(t, network.Stop())
+	assert.Nil(t, snsContainer.relay)
+}
+
+func TestSnsDockerContainer_StopsRelayWhenConfigIsInvalid(t *testing.T) {
+	snsContainer := &SnsDockerContainer{Config: SnsDockerContainerConfig{
+		Hostname: "sns",
+		Port:     9911,
+		Subscriptions: []SnsSubscription{
+			{Topic: "refunds", Lambda: &LambdaDockerContainer{Config: LambdaDockerContainerConfig{FunctionName: "refunder"}}},
+			{Topic: "orders"},
+		},
+	}}
+	network := fakeNetwork(&FakeContainerProvider{}, snsContainer)
+
+	assert.Error(t, network.StartWithDelay(0))
+
+	assert.Nil(t, snsContainer.relay)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
+	"github.com/mikebharris/testcontainernetwork-go/clients"
 	"github.com/testcontainers/testcontainers-go"
 )
 
+const sqsImage = "softwaremill/elasticmq"
+
 type SqsDockerContainerConfig struct {
 	Hostname   string
 	Port       int
@@ -18,10 +21,14 @@ type SqsDockerContainer struct {
 	Config SqsDockerContainerConfig
 }
 
+func (c *SqsDockerContainer) Image() string {
+	return sqsImage
+}
+
 func (c *SqsDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 	req := testcontainers.ContainerRequest{
-		Image:        "softwaremill/elasticmq",
+		Image:        c.Image(),
 		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
 		Name:         c.Config.Hostname,
 		Hostname:     c.Config.Hostname,
@@ -31,20 +38,24 @@ func (c *SqsDockerContainer) StartUsing(ctx context.Context, dockerNetwork *test
 			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
 		},
 	}
-	var err error
-	if c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	}); err != nil {
-		return fmt.Errorf("creating container: %w", err)
-	}
+	return c.createAndStart(ctx, req, func(ctx context.Context) error {
+		if err := c.testContainer.CopyFileToContainer(ctx, c.Config.ConfigFile, "/opt/elasticmq.conf", configFileMode); err != nil {
+			return fmt.Errorf("copying config file to docker container: %w", err)
+		}
+		return nil
+	})
+}
 
-	if err = c.testContainer.CopyFileToContainer(ctx, c.Config.ConfigFile, "/opt/elasticmq.conf", 365); err != nil {
-		return fmt.Errorf("copying config file to docker container: %w", err)
-	}
+// Snapshot does nothing as the queues are restored by being purged
+func (c *SqsDockerContainer) Snapshot(context.Context) error {
+	return nil
+}
 
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+// Restore purges all the messages from the queues
+func (c *SqsDockerContainer) Restore(context.Context) error {
+	sqsClient, err := clients.SqsClient{}.New(c.MappedPort())
+	if err != nil {
+		return fmt.Errorf("creating SQS client: %w", err)
 	}
-	return nil
+	return sqsClient.PurgeAllQueues()
 }
@@ -0,0 +1,238 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/mikebharris/testcontainernetwork-go/clients"
+	"strconv"
+	"time"
+)
+
+type SqsEventSourceMappingConfig struct {
+	Queue string
+	// BatchSize is the most messages that the Lambda is invoked with at once, which defaults to 10
+	BatchSize int
+	// MaximumBatchingWindow is how long to wait for a batch to fill up before invoking the Lambda with what there is;
+	// without one, the Lambda is invoked with the messages from a single receive, which is at most 10
+	MaximumBatchingWindow time.Duration
+	// ReportBatchItemFailures makes the Lambda's events.SQSEventResponse decide which messages failed and are left on
+	// the queue, rather than the whole batch failing or succeeding together.  As in AWS, the whole batch fails if the
+	// response lists a message that isn't in it
+	ReportBatchItemFailures bool
+	// MaximumReceiveCount is how many times a message that the Lambda fails to process is received before the mapping
+	// deletes it, as a redrive policy would move it to a dead-letter queue, so that it isn't retried for the rest of a
+	// test.  The default of 0 leaves failed messages on the queue, subject to any redrive policy of its own
+	MaximumReceiveCount int
+}
+
+// SqsEventSourceMapping triggers a Lambda with the messages on an SQS queue, as an event source mapping does in AWS.
+// Once the network is ready, it polls the queue, invokes the Lambda with batches of messages and deletes those that
+// are processed successfully; messages in batches that fail stay on the queue, to be retried once their visibility
+// timeout expires
+type SqsEventSourceMapping struct {
+	Config SqsEventSourceMappingConfig
+	Sqs    *SqsDockerContainer
+	Lambda *LambdaDockerContainer
+
+	queue    sqsMessageQueue
+	queueUrl string
+	region   string
+	invoke   func(ctx context.Context, event any) (LambdaResponse, error)
+	poller   eventSourcePoller
+}
+
+// sqsMessageQueue is the part of clients.SqsClient that the mapping uses
+type sqsMessageQueue interface {
+	QueueUrl(ctx context.Context, queue string) (string, error)
+	ReceiveMessages(ctx context.Context, queueUrl string, maxNumberOfMessages int32, waitTime time.Duration) ([]types.Message, error)
+	DeleteMessages(ctx context.Context, queueUrl string, messages []types.Message) error
+}
+
+// WithSqsEventSourceMapping starts the mapping polling its queue once the network is ready, and stops it before the
+// containers are stopped, or are rolled back because the network failed to start after the mapping did
+func (n NetworkOfDockerContainers) WithSqsEventSourceMapping(mapping *SqsEventSourceMapping) NetworkOfDockerContainers {
+	return n.WithHooks(LifecycleHooks{
+		AfterReady: []LifecycleHook{mapping.Start},
+		BeforeStop: []LifecycleHook{mapping.Stop},
+		OnFailure: []FailureHook{func(ctx context.Context, _ error) {
+			_ = mapping.Stop(ctx)
+		}},
+	})
+}
+
+// Start polls the queue in the background until Stop is called
+func (m *SqsEventSourceMapping) Start(context.Context) error {
+	sqsClient, err := clients.SqsClient{}.New(m.Sqs.MappedPort())
+	if err != nil {
+		return fmt.Errorf("creating SQS client: %w", err)
+	}
+	m.queue = sqsClient
+	m.region = m.Lambda.Config.Region
+	m.invoke = m.Lambda.Invoke
+	m.poller.start(m.Poll)
+	return nil
+}
+
+// Stop stops polling the queue, waiting for any invocation in progress to finish
+func (m *SqsEventSourceMapping) Stop(context.Context) error {
+	m.poller.stop()
+	return nil
+}
+
+// Errors returns the errors that polling has met, such as the Lambda returning a function error
+func (m *SqsEventSourceMapping) Errors() []error {
+	return m.poller.errors()
+}
+
+// Poll receives a batch of messages from the queue and, if there are any, invokes the Lambda with them and deletes
+// those that it processes, returning the number of messages in the batch
+func (m *SqsEventSourceMapping) Poll(ctx context.Context) (int, error) {
+	if m.queueUrl == "" {
+		queueUrl, err := m.queue.QueueUrl(ctx, m.Config.Queue)
+		if err != nil {
+			return 0, err
+		}
+		m.queueUrl = queueUrl
+	}
+
+	messages, err := m.receiveBatch(ctx)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	response, err := m.invoke(ctx, m.sqsEvent(messages))
+	var processed []types.Message
+	if err == nil {
+		processed = messages
+		if m.Config.ReportBatchItemFailures {
+			processed, err = processedMessages(messages, response)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("invoking Lambda with messages from %s: %w", m.Config.Queue, err)
+		if exhausted := m.exhausted(messages, processed); len(exhausted) > 0 {
+			processed = append(processed, exhausted...)
+			err = fmt.Errorf("%w; deleting %d messages received %d times", err, len(exhausted), m.Config.MaximumReceiveCount)
+		}
+	}
+	if len(processed) > 0 {
+		if err := m.queue.DeleteMessages(ctx, m.queueUrl, processed); err != nil {
+			return len(messages), err
+		}
+	}
+	return len(messages), err
+}
+
+// exhausted returns the messages that weren't processed and have been received MaximumReceiveCount times
+func (m *SqsEventSourceMapping) exhausted(messages []types.Message, processed []types.Message) []types.Message {
+	if m.Config.MaximumReceiveCount <= 0 {
+		return nil
+	}
+	done := map[string]bool{}
+	for _, message := range processed {
+		done[aws.ToString(message.MessageId)] = true
+	}
+	var exhausted []types.Message
+	for _, message := range messages {
+		receiveCount, _ := strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+		if !done[aws.ToString(message.MessageId)] && receiveCount >= m.Config.MaximumReceiveCount {
+			exhausted = append(exhausted, message)
+		}
+	}
+	return exhausted
+}
+
+// receiveBatch receives messages until there are BatchSize or the batching window has passed, waiting up to a
+// second for the first if the queue is empty
+func (m *SqsEventSourceMapping) receiveBatch(ctx context.Context) ([]types.Message, error) {
+	batchSize := m.Config.BatchSize
+	if batchSize == 0 {
+		batchSize = 10
+	}
+	deadline := time.Now().Add(m.Config.MaximumBatchingWindow)
+	var messages []types.Message
+	for len(messages) < batchSize {
+		waitTime := time.Second
+		if len(messages) > 0 {
+			waitTime = 0
+		}
+		received, err := m.queue.ReceiveMessages(ctx, m.queueUrl, int32(min(batchSize-len(messages), 10)), waitTime)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, received...)
+		if len(messages) == 0 {
+			return nil, nil
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		if len(received) == 0 {
+			time.Sleep(min(100*time.Millisecond, time.Until(deadline)))
+		}
+	}
+	return messages, nil
+}
+
+func (m *SqsEventSourceMapping) sqsEvent(messages []types.Message) events.SQSEvent {
+	builder := NewSqsEvent(m.Config.Queue).WithRegion(m.region)
+	var event events.SQSEvent
+	for _, message := range messages {
+		attributes := map[string]events.SQSMessageAttribute{}
+		for name, value := range message.MessageAttributes {
+			attributes[name] = events.SQSMessageAttribute{
+				StringValue:      value.StringValue,
+				BinaryValue:      value.BinaryValue,
+				StringListValues: value.StringListValues,
+				BinaryListValues: value.BinaryListValues,
+				DataType:         aws.ToString(value.DataType),
+			}
+		}
+		record := builder.WithMessageAttributes(aws.ToString(message.Body), attributes).Build().Records[0]
+		record.MessageId = aws.ToString(message.MessageId)
+		record.ReceiptHandle = aws.ToString(message.ReceiptHandle)
+		record.Md5OfBody = aws.ToString(message.MD5OfBody)
+		record.Md5OfMessageAttributes = aws.ToString(message.MD5OfMessageAttributes)
+		for name, value := range message.Attributes {
+			record.Attributes[name] = value
+		}
+		event.Records = append(event.Records, record)
+	}
+	return event
+}
+
+// processedMessages returns the messages that the Lambda didn't report as failures in its events.SQSEventResponse,
+// along with an error if any failed, or none of them if it reported a failure of a message that wasn't in the batch
+func processedMessages(messages []types.Message, response LambdaResponse) ([]types.Message, error) {
+	var sqsEventResponse events.SQSEventResponse
+	if len(response.Payload) > 0 && string(response.Payload) != "null" {
+		if err := json.Unmarshal(response.Payload, &sqsEventResponse); err != nil {
+			return nil, fmt.Errorf("unmarshalling batch item failures: %w", err)
+		}
+	}
+	inBatch := map[string]bool{}
+	for _, message := range messages {
+		inBatch[aws.ToString(message.MessageId)] = true
+	}
+	failed := map[string]bool{}
+	for _, failure := range sqsEventResponse.BatchItemFailures {
+		if !inBatch[failure.ItemIdentifier] {
+			return nil, fmt.Errorf("batch item failure %q isn't a message in the batch, so all %d messages failed", failure.ItemIdentifier, len(messages))
+		}
+		failed[failure.ItemIdentifier] = true
+	}
+	var processed []types.Message
+	for _, message := range messages {
+		if !failed[aws.ToString(message.MessageId)] {
+			processed = append(processed, message)
+		}
+	}
+	if len(failed) > 0 {
+		return processed, fmt.Errorf("%d of %d messages failed", len(messages)-len(processed), len(messages))
+	}
+	return processed, nil
+}
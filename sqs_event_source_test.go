@@ -0,0 +1,199 @@
+package testcontainernetwork
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeSqsQueue is an in-memory queue of messages that are received in order and deleted by receipt handle
+type fakeSqsQueue struct {
+	messages []types.Message
+	received int
+	deleted  []string
+}
+
+func (q *fakeSqsQueue) QueueUrl(_ context.Context, queue string) (string, error) {
+	return "http://sqs:9324/queue/" + queue, nil
+}
+
+func (q *fakeSqsQueue) ReceiveMessages(_ context.Context, _ string, maxNumberOfMessages int32, _ time.Duration) ([]types.Message, error) {
+	n := min(int(maxNumberOfMessages), len(q.messages)-q.received)
+	received := q.messages[q.received : q.received+n]
+	q.received += n
+	return received, nil
+}
+
+func (q *fakeSqsQueue) DeleteMessages(_ context.Context, _ string, messages []types.Message) error {
+	for _, message := range messages {
+		q.deleted = append(q.deleted, aws.ToString(message.MessageId))
+	}
+	return nil
+}
+
+func fakeSqsQueueWith(count int) *fakeSqsQueue {
+	queue := &fakeSqsQueue{}
+	for i := 1; i <= count; i++ {
+		queue.messages = append(queue.messages, types.Message{
+			MessageId:     aws.String(fmt.Sprint("message-", i)),
+			ReceiptHandle: aws.String(fmt.Sprint("receipt-", i)),
+			Body:          aws.String(fmt.Sprint("body ", i)),
+			Attributes:    map[string]string{"ApproximateReceiveCount": "2"},
+		})
+	}
+	return queue
+}
+
+func TestSqsEventSourceMapping_InvokesLambdaWithBatchAndDeletesMessages(t *testing.T) {
+	queue := fakeSqsQueueWith(3)
+	var invokedWith []events.SQSEvent
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders", BatchSize: 2},
+		queue:  queue,
+		region: "us-east-2",
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			invokedWith = append(invokedWith, event.(events.SQSEvent))
+			return LambdaResponse{StatusCode: 200, Payload: []byte("null")}, nil
+		},
+	}
+
+	count, err := mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, invokedWith[0].Records, 2)
+	record := invokedWith[0].Records[1]
+	assert.Equal(t, "message-2", record.MessageId)
+	assert.Equal(t, "receipt-2", record.ReceiptHandle)
+	assert.Equal(t, "body 2", record.Body)
+	assert.Equal(t, "2", record.Attributes["ApproximateReceiveCount"])
+	assert.Equal(t, "arn:aws:sqs:us-east-2:000000000000:orders", record.EventSourceARN)
+	assert.Equal(t, "us-east-2", record.AWSRegion)
+	assert.Equal(t, []string{"message-1", "message-2"}, queue.deleted)
+}
+
+func TestSqsEventSourceMapping_LeavesMessagesOnQueueWhenLambdaFails(t *testing.T) {
+	queue := fakeSqsQueueWith(2)
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders"},
+		queue:  queue,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			return LambdaResponse{}, &LambdaFunctionError{ErrorMessage: "database is down"}
+		},
+	}
+
+	_, err := mapping.Poll(context.Background())
+
+	assert.EqualError(t, err, "invoking Lambda with messages from orders: Lambda function error: database is down")
+	assert.Empty(t, queue.deleted)
+}
+
+func TestSqsEventSourceMapping_HonoursBatchItemFailures(t *testing.T) {
+	queue := fakeSqsQueueWith(3)
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders", ReportBatchItemFailures: true},
+		queue:  queue,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			payload, _ := json.Marshal(events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "message-2"}}})
+			return LambdaResponse{StatusCode: 200, Payload: payload}, nil
+		},
+	}
+
+	count, err := mapping.Poll(context.Background())
+
+	assert.EqualError(t, err, "invoking Lambda with messages from orders: 1 of 3 messages failed")
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []string{"message-1", "message-3"}, queue.deleted)
+}
+
+func TestSqsEventSourceMapping_FailsWholeBatchWhenBatchItemFailureIsUnknown(t *testing.T) {
+	queue := fakeSqsQueueWith(2)
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders", ReportBatchItemFailures: true},
+		queue:  queue,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			payload, _ := json.Marshal(events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "message-1"}, {ItemIdentifier: ""}}})
+			return LambdaResponse{StatusCode: 200, Payload: payload}, nil
+		},
+	}
+
+	_, err := mapping.Poll(context.Background())
+
+	assert.EqualError(t, err, `invoking Lambda with messages from orders: batch item failure "" isn't a message in the batch, so all 2 messages failed`)
+	assert.Empty(t, queue.deleted)
+}
+
+func TestSqsEventSourceMapping_DeletesFailingMessagesReceivedMaximumReceiveCountTimes(t *testing.T) {
+	queue := fakeSqsQueueWith(3)
+	queue.messages[1].Attributes["ApproximateReceiveCount"] = "3"
+	queue.messages[2].Attributes = map[string]string{"ApproximateReceiveCount": "4"}
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders", ReportBatchItemFailures: true, MaximumReceiveCount: 3},
+		queue:  queue,
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			payload, _ := json.Marshal(events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "message-1"}, {ItemIdentifier: "message-2"}}})
+			return LambdaResponse{StatusCode: 200, Payload: payload}, nil
+		},
+	}
+
+	_, err := mapping.Poll(context.Background())
+
+	assert.EqualError(t, err, "invoking Lambda with messages from orders: 2 of 3 messages failed; deleting 1 messages received 3 times")
+	assert.Equal(t, []string{"message-3", "message-2"}, queue.deleted)
+}
+
+func TestSqsEventSourceMapping_FillsBatchWithinWindow(t *testing.T) {
+	queue := fakeSqsQueueWith(25)
+	var batchSize int
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders", BatchSize: 20, MaximumBatchingWindow: time.Second},
+		queue:  queue,
+		invoke: func(_ context.Context, event any) (LambdaResponse, error) {
+			batchSize = len(event.(events.SQSEvent).Records)
+			return LambdaResponse{StatusCode: 200}, nil
+		},
+	}
+
+	_, err := mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20, batchSize)
+}
+
+func TestSqsEventSourceMapping_DoesNothingWhenQueueIsEmpty(t *testing.T) {
+	mapping := &SqsEventSourceMapping{
+		Config: SqsEventSourceMappingConfig{Queue: "orders"},
+		queue:  fakeSqsQueueWith(0),
+		invoke: func(context.Context, any) (LambdaResponse, error) {
+			t.Fatal("Lambda invoked with no messages")
+			return LambdaResponse{}, nil
+		},
+	}
+
+	count, err := mapping.Poll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestSqsEventSourceMapping_StopsWhenNetworkFailsToStartAfterIt(t *testing.T) {
+	sqsContainer := &SqsDockerContainer{Config: SqsDockerContainerConfig{Hostname: "sqs", Port: 9324}}
+	lambdaContainer := &LambdaDockerContainer{Config: LambdaDockerContainerConfig{Executable: "main", FunctionName: "orders"}}
+	mapping := &SqsEventSourceMapping{Config: SqsEventSourceMappingConfig{Queue: "orders"}, Sqs: sqsContainer, Lambda: lambdaContainer}
+	network := fakeNetwork(&FakeContainerProvider{}, sqsContainer, lambdaContainer).
+		WithSqsEventSourceMapping(mapping).
+		WithHooks(LifecycleHooks{AfterReady: []LifecycleHook{func(context.Context) error {
+			return errors.New("address already in use")
+		}}})
+
+	assert.ErrorContains(t, network.StartWithDelay(0), "address already in use")
+
+	assert.Nil(t, mapping.poller.cancel)
+}
@@ -5,9 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/testcontainers/testcontainers-go"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -15,10 +15,15 @@ import (
 	"time"
 )
 
+const wiremockImage = "wiremock/wiremock"
+
 type WiremockDockerContainerConfig struct {
 	Hostname        string
 	Port            int
 	ConfigFilesPath string
+	// ConfigFiles, if set, are the mappings that are copied into the container instead of mounting ConfigFilesPath,
+	// and can be embedded in the test binary
+	ConfigFiles fs.FS
 }
 
 type WiremockDockerContainer struct {
@@ -26,44 +31,33 @@ type WiremockDockerContainer struct {
 	Config WiremockDockerContainerConfig
 }
 
-func (c *WiremockDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
-	wd, err := os.Getwd()
-	if err != nil {
-		panic(err)
-	}
+func (c *WiremockDockerContainer) Image() string {
+	return wiremockImage
+}
 
+func (c *WiremockDockerContainer) StartUsing(ctx context.Context, dockerNetwork *testcontainers.DockerNetwork) error {
 	c.internalServicePort = c.Config.Port
 
 	req := testcontainers.ContainerRequest{
-		Image:        "wiremock/wiremock",
+		Image:        c.Image(),
 		ExposedPorts: []string{fmt.Sprintf("%d/tcp", c.internalServicePort)},
 		Name:         c.Config.Hostname,
 		Hostname:     c.Config.Hostname,
 		Networks:     []string{dockerNetwork.Name},
 		HostConfigModifier: func(config *container.HostConfig) {
 			config.NetworkMode = container.NetworkMode(dockerNetwork.Name)
-			config.Mounts = []mount.Mount{
-				{
-					Type:     mount.TypeBind,
-					Source:   path.Join(wd, c.Config.ConfigFilesPath),
-					Target:   "/home/wiremock/mappings/",
-					ReadOnly: true,
-				},
-			}
 		},
 	}
-	c.testContainer, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          false,
-	})
-	if err != nil {
-		return fmt.Errorf("creating container: %w", err)
-	}
-
-	if err := c.testContainer.Start(ctx); err != nil {
-		return fmt.Errorf("starting container: %w", err)
+	if c.Config.ConfigFiles != nil {
+		c.copyDirectoryOnStart(c.Config.ConfigFiles, "/home/wiremock/mappings")
+	} else {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		c.mountHostDirectory(&req, path.Join(wd, c.Config.ConfigFilesPath), "/home/wiremock/mappings")
 	}
-	return nil
+	return c.createAndStart(ctx, req)
 }
 
 func (c *WiremockDockerContainer) GetAdminStatus() (WiremockAdminStatus, error) {
@@ -96,6 +90,45 @@ func (c *WiremockDockerContainer) GetAdminStatus() (WiremockAdminStatus, error)
 	return wiremockAdminStatus, nil
 }
 
+// Snapshot does nothing as Wiremock is restored to the mappings it was started with
+func (c *WiremockDockerContainer) Snapshot(context.Context) error {
+	return nil
+}
+
+// Restore clears the journal of requests and resets the mappings to those in the config files
+func (c *WiremockDockerContainer) Restore(ctx context.Context) error {
+	if err := c.adminRequest(ctx, http.MethodDelete, "requests"); err != nil {
+		return fmt.Errorf("resetting request journal: %w", err)
+	}
+	if err := c.adminRequest(ctx, http.MethodPost, "mappings/reset"); err != nil {
+		return fmt.Errorf("resetting mappings: %w", err)
+	}
+	return nil
+}
+
+func (c *WiremockDockerContainer) adminRequest(ctx context.Context, method string, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("http://localhost:%d/__admin/%s", c.MappedPort(), endpoint), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	var client = http.Client{
+		Timeout: time.Second * 30,
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making http request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, body)
+	}
+	return nil
+}
+
 type WiremockAdminStatus struct {
 	Requests               []WiremockAdminRequest `json:"requests"`
 	Meta                   WiremockAdminMeta      `json:"meta"`